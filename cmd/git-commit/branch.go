@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"git-commit/internal/branch"
+	"git-commit/internal/git"
+)
+
+// runBranch validates a branch name and optionally creates and switches to it
+func runBranch(args []string) int {
+	fs := newFlagSet("branch")
+	checkout := fs.Bool("checkout", false, "create and switch to the branch after validating it")
+	assumeYes := fs.Bool("yes", false, "do not ask for confirmation before creating the branch")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return flagError(err)
+	}
+	if len(positional) != 1 {
+		return usageError("branch expects exactly one branch name")
+	}
+
+	name := positional[0]
+	if err := branch.Validate(name); err != nil {
+		fmt.Fprintf(os.Stderr, "git-commit: %v\n", err)
+		return exitInvalid
+	}
+	if !*checkout {
+		fmt.Printf("Branch name '%s' is valid.\n", name)
+		return exitOK
+	}
+
+	return checkoutBranch(name, *assumeYes)
+}
+
+// checkoutBranch creates and switches to a validated branch once the user confirms
+func checkoutBranch(name string, assumeYes bool) int {
+	if !git.IsInsideWorkTree() {
+		fmt.Fprintln(os.Stderr, "git-commit: not a git repository")
+		return exitError
+	}
+
+	if current, _ := git.CurrentBranch(); current == name {
+		fmt.Printf("Already on branch '%s'.\n", name)
+		return exitOK
+	}
+
+	if !assumeYes && !confirm(fmt.Sprintf("Create and switch to branch '%s'?", name)) {
+		fmt.Println("Branch not created.")
+		return exitOK
+	}
+
+	if err := git.CreateBranch(name); err != nil {
+		fmt.Fprintf(os.Stderr, "git-commit: %v\n", err)
+		return exitError
+	}
+	fmt.Printf("Switched to a new branch '%s'.\n", name)
+	return exitOK
+}
+
+// confirm asks a yes/no question on stderr and reads the answer from stdin, defaulting to no
+func confirm(question string) bool {
+	fmt.Fprintf(os.Stderr, "%s [y/N] ", question)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && answer == "" {
+		fmt.Fprintln(os.Stderr)
+		return false
+	}
+
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,366 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"git-commit/internal/clipboard"
+	"git-commit/internal/config"
+	"git-commit/internal/diff"
+	"git-commit/internal/git"
+	"git-commit/internal/help"
+	"git-commit/internal/output"
+	"git-commit/internal/prompt"
+)
+
+// defaultIgnoreFile is the content written to .git-commit/ignore by init
+const defaultIgnoreFile = `# Patterns of staged files to leave out of the generated prompt
+# Comments start with #
+*.lock
+*.min.js
+`
+
+// defaultConfigFile is the content written to .git-commit/config.yaml by init
+const defaultConfigFile = `# git-commit settings, merged on top of ~/.config/git-commit/config.yaml
+# prompt: api              # custom prompt used when no prompt name is given
+# output: clipboard        # clipboard, stdout, file:<path>, editor, commit-template or json
+# clipboard: auto          # auto, none, wl-copy, clip.exe, tmux, pbcopy, clip, xclip, xsel or osc52
+# provider:
+#   name: openai           # openai, anthropic or ollama
+#   model: gpt-4o-mini
+#   base_url: https://api.openai.com/v1
+#   timeout: 60s
+#   max_tokens: 1024
+# diff:
+#   context_lines: 3       # lines of context around each change
+#   max_bytes: 50000       # truncate larger diffs, 0 for no limit
+#   max_tokens: 16000      # token budget of the diff, 0 to disable
+# lint:
+#   header-max-length: error 72
+`
+
+// runPrompt generates the AI prompt for the staged changes and delivers it to the configured output
+func runPrompt(args []string) int {
+	fs := newFlagSet("prompt")
+	printOnly := fs.Bool("print", false, "print the prompt to stdout, short for -output stdout")
+	outputSpec := addOutputFlag(fs, settings.Output)
+	src := addSourceFlag(fs)
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return flagError(err)
+	}
+	if len(positional) > 1 {
+		return usageError("prompt accepts at most one prompt name, got %d", len(positional))
+	}
+
+	var promptName string
+	if len(positional) == 1 {
+		promptName = positional[0]
+	}
+	repo := openRepo()
+	promptName = prompt.PromptName(repo, promptName)
+	if promptName != "" && !hasCustomPrompt(repo, promptName) {
+		return usageError("custom prompt %q not found in .git-commit/custom-instructions", promptName)
+	}
+	if repo == nil {
+		return notARepository()
+	}
+
+	var text string
+	if promptName != "" {
+		debugf("using custom prompt %q", promptName)
+		text, err = prompt.GetAIPrompt(repo, promptName, *src)
+	} else {
+		debugf("using default prompt with %s", *src)
+		var rawPrompt, diffOutput string
+		rawPrompt, err = prompt.GetChangesAiPrompt(repo)
+		if err == nil {
+			diffOutput, err = diff.GetDiffOutput(repo, *src)
+		}
+		text = rawPrompt + "\n\n" + diffOutput
+	}
+	if err != nil {
+		return runtimeError(err)
+	}
+
+	if *printOnly {
+		*outputSpec = config.OutputStdout
+	}
+	return writeOutput(*outputSpec, output.Result{Kind: output.KindPrompt, Prompt: promptName, Source: src.String(), Text: text})
+}
+
+// addOutputFlag registers -output, which selects the sink of the result, spec when it is not given
+func addOutputFlag(fs *flag.FlagSet, spec string) *string {
+	fs.Func("output", "`sink` of the result: "+strings.Join(config.Outputs(), ", "), func(value string) error {
+		if _, _, err := config.ParseOutput(value); err != nil {
+			return err
+		}
+		spec = value
+		return nil
+	})
+	return &spec
+}
+
+// writeOutput delivers r to the sink of an output specification
+func writeOutput(spec string, r output.Result) int {
+	sink, err := output.New(spec, os.Stdout, os.Stderr)
+	if err != nil {
+		return usageError("%v", err)
+	}
+	if err := sink.Write(r); err != nil {
+		return runtimeError(err)
+	}
+	return exitOK
+}
+
+// addSourceFlag registers -diff, which selects the changes the prompt describes
+func addSourceFlag(fs *flag.FlagSet) *diff.Source {
+	src := &diff.Source{Kind: diff.SourceStaged}
+	fs.Func("diff", "`source` of the changes to describe: staged (default), unstaged, <from>..<to>, commit:<rev>, stash[:<n>] or - for a patch on stdin", func(spec string) error {
+		parsed, err := diff.ParseSource(spec)
+		if err != nil {
+			return err
+		}
+		*src = parsed
+		return nil
+	})
+	return src
+}
+
+// runList prints the available custom prompts with the description and tags of their front matter
+func runList(args []string) int {
+	fs := newFlagSet("list")
+	long := fs.Bool("l", false, "also show the context, model, diff budget, output format and base prompt")
+	namesOnly := fs.Bool("q", false, "print only the prompt names")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return flagError(err)
+	}
+	if len(positional) > 0 {
+		return usageError("list does not accept arguments")
+	}
+
+	repo := openRepo()
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	for _, promptName := range help.GetAvailableCustomPrompts(repo) {
+		if *namesOnly {
+			fmt.Println(promptName)
+			continue
+		}
+		meta, err := prompt.Metadata(repo, promptName)
+		description := meta.Description
+		if err != nil {
+			description = fmt.Sprintf("(%v)", err)
+		}
+		if len(meta.Tags) > 0 {
+			description += fmt.Sprintf("  [%s]", strings.Join(meta.Tags, ", "))
+		}
+		fmt.Fprintf(w, "%s\t%s\n", promptName, strings.TrimSpace(description))
+		if *long {
+			for _, detail := range promptDetails(meta) {
+				fmt.Fprintf(w, "\t  %s\n", detail)
+			}
+		}
+	}
+	w.Flush()
+	return exitOK
+}
+
+// promptDetails describes the front matter settings of a prompt besides its description and tags
+func promptDetails(meta prompt.FrontMatter) []string {
+	var details []string
+	if meta.Extends != "" {
+		details = append(details, "extends: "+meta.Extends)
+	}
+	if len(meta.Context) > 0 {
+		details = append(details, "context: "+strings.Join(meta.Context, ", "))
+	}
+	if meta.Model != "" {
+		details = append(details, "model: "+meta.Model)
+	}
+	if meta.MaxDiffTokens > 0 {
+		details = append(details, fmt.Sprintf("max diff tokens: %d", meta.MaxDiffTokens))
+	}
+	if meta.Output != "" {
+		details = append(details, "output: "+meta.Output)
+	}
+	return details
+}
+
+// runShow prints the raw content of a custom prompt, or the default prompt when no name is given
+func runShow(args []string) int {
+	fs := newFlagSet("show")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return flagError(err)
+	}
+	if len(positional) > 1 {
+		return usageError("show accepts at most one prompt name, got %d", len(positional))
+	}
+
+	repo := openRepo()
+	if len(positional) == 0 {
+		text, err := prompt.GetChangesAiPrompt(repo)
+		if err != nil {
+			return runtimeError(err)
+		}
+		fmt.Println(text)
+		return exitOK
+	}
+
+	content, err := prompt.LoadCustomPrompt(repo, positional[0])
+	if err != nil {
+		return usageError("%v", err)
+	}
+	fmt.Print(content)
+	return exitOK
+}
+
+// runInit creates the .git-commit folder with a starter ignore file and custom-instructions directory
+// at the repository root, or in the current directory outside a repository
+func runInit(args []string) int {
+	fs := newFlagSet("init")
+	force := fs.Bool("force", false, "overwrite existing files")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return flagError(err)
+	}
+	if len(positional) > 0 {
+		return usageError("init does not accept arguments")
+	}
+
+	repo := openRepo()
+	if err := os.MkdirAll(repo.ConfigPath("custom-instructions"), 0755); err != nil {
+		fmt.Fprintf(os.Stderr, "git-commit: failed to create .git-commit folder: %v\n", err)
+		return exitError
+	}
+
+	files := []struct {
+		name    string
+		content string
+	}{
+		{filepath.Join(git.ConfigDir, config.FileName), defaultConfigFile},
+		{filepath.Join(git.ConfigDir, "ignore"), defaultIgnoreFile},
+		{filepath.Join(git.ConfigDir, "prompt.md"), ""},
+	}
+	for _, file := range files {
+		path := repo.Path(file.name)
+		if _, err := os.Stat(path); err == nil && !*force {
+			fmt.Printf("  exists  %s\n", file.name)
+			continue
+		}
+		if err := os.WriteFile(path, []byte(file.content), 0644); err != nil {
+			fmt.Fprintf(os.Stderr, "git-commit: failed to write %s: %v\n", file.name, err)
+			return exitError
+		}
+		fmt.Printf("  created %s\n", file.name)
+	}
+
+	fmt.Println("Leave .git-commit/prompt.md empty to use the default prompt.")
+	return exitOK
+}
+
+// runDoctor checks the environment git-commit depends on and reports any problems
+func runDoctor(args []string) int {
+	fs := newFlagSet("doctor")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return flagError(err)
+	}
+	if len(positional) > 0 {
+		return usageError("doctor does not accept arguments")
+	}
+
+	failed := false
+	report := func(status, name, detail string) {
+		fmt.Printf("%-6s %-16s %s\n", "["+status+"]", name, detail)
+		if status == "fail" {
+			failed = true
+		}
+	}
+
+	if version, err := git.Version(); err != nil {
+		report("fail", "git", "git is not installed or not in PATH")
+	} else {
+		report("ok", "git", version)
+	}
+
+	repo := openRepo()
+	if _, err := config.Load(repo.Path()); err != nil {
+		report("fail", "config", err.Error())
+	} else {
+		var files, found []string
+		if dir := config.UserDir(); dir != "" {
+			files = append(files, filepath.Join(dir, config.FileName))
+		}
+		if repo != nil {
+			files = append(files, repo.ConfigPath(config.FileName))
+		}
+		for _, file := range files {
+			if _, err := os.Stat(file); err == nil {
+				found = append(found, file)
+			}
+		}
+		if len(found) == 0 {
+			report("ok", "config", "no config.yaml, using defaults")
+		} else {
+			report("ok", "config", strings.Join(found, ", "))
+		}
+	}
+
+	if repo != nil {
+		detail := repo.Root
+		switch {
+		case repo.IsSubmodule():
+			detail += " (submodule of " + repo.Superproject + ")"
+		case repo.IsWorktree():
+			detail += " (linked worktree of " + repo.CommonDir + ")"
+		}
+		report("ok", "repository", detail)
+		if files, err := git.GetStagedFiles(); err != nil {
+			report("fail", "staged changes", err.Error())
+		} else if len(files) == 0 {
+			report("warn", "staged changes", "nothing staged, use 'git add' first")
+		} else {
+			report("ok", "staged changes", fmt.Sprintf("%d staged files", len(files)))
+		}
+		if matcher, err := git.LoadIgnore(repo, nil); err != nil {
+			report("fail", "ignore files", err.Error())
+		} else {
+			report("ok", "ignore files", fmt.Sprintf("%d patterns in the global and repository files", len(matcher.Patterns())))
+		}
+	} else {
+		report("fail", "repository", "not inside a git working tree")
+	}
+
+	if customPrompts := help.GetAvailableCustomPrompts(repo); len(customPrompts) == 0 {
+		report("warn", "custom prompts", "none found in .git-commit/custom-instructions")
+	} else {
+		report("ok", "custom prompts", fmt.Sprintf("%d available", len(customPrompts)))
+	}
+
+	if backend := clipboard.Current(); backend == nil {
+		report("warn", "clipboard", fmt.Sprintf("no backend available (clipboard: %s), prompts will be printed instead", settings.Clipboard))
+	} else {
+		report("ok", "clipboard", fmt.Sprintf("%s (clipboard: %s)", backend.Name(), settings.Clipboard))
+	}
+
+	if failed {
+		return exitError
+	}
+	return exitOK
+}
+
+// hasCustomPrompt reports whether a custom prompt with the given name exists in repo
+func hasCustomPrompt(repo *git.Repo, promptName string) bool {
+	for _, name := range help.GetAvailableCustomPrompts(repo) {
+		if name == promptName {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"git-commit/internal/diff"
+	"git-commit/internal/git"
+)
+
+// commitFlagNames lists the flags registered by addCommitFlags
+var commitFlagNames = []string{"amend", "signoff", "sign", "sign-key", "no-sign", "author", "no-verify"}
+
+// commitFlags holds the flags shared by commands that create a commit, passed through to git commit
+type commitFlags struct {
+	amend    *bool
+	signoff  *bool
+	sign     *bool
+	signKey  *string
+	noSign   *bool
+	author   *string
+	noVerify *bool
+}
+
+// addCommitFlags registers the commit flags on fs
+func addCommitFlags(fs *flag.FlagSet) commitFlags {
+	return commitFlags{
+		amend:    fs.Bool("amend", false, "replace the last commit, describing its changes together with the staged ones"),
+		signoff:  fs.Bool("signoff", false, "add a Signed-off-by trailer"),
+		sign:     fs.Bool("sign", false, "sign the commit with GPG or SSH, as configured by gpg.format"),
+		signKey:  fs.String("sign-key", "", "key to sign the commit with, implies -sign"),
+		noSign:   fs.Bool("no-sign", false, "do not sign the commit, even when commit.gpgSign is set"),
+		author:   fs.String("author", "", "override the commit author, \"Name <email>\""),
+		noVerify: fs.Bool("no-verify", false, "skip the pre-commit and commit-msg hooks"),
+	}
+}
+
+// check reports conflicting commit flags
+func (f commitFlags) check() error {
+	if (*f.sign || *f.signKey != "") && *f.noSign {
+		return fmt.Errorf("-sign and -sign-key cannot be combined with -no-sign")
+	}
+	return nil
+}
+
+// source returns the changes the commit will contain: with -amend, those of the last commit
+// and the staged ones, otherwise src
+func (f commitFlags) source(src diff.Source) (diff.Source, error) {
+	if *f.amend {
+		// The parent of a root commit is the empty tree
+		parent, err := git.Parent("HEAD")
+		if err != nil {
+			return src, fmt.Errorf("-amend needs a commit to amend: %w", err)
+		}
+		return diff.Source{Kind: diff.SourceStaged, Rev: parent}, nil
+	}
+	return src, nil
+}
+
+// commit commits the staged changes with message as the flags ask and prints git's summary
+func (f commitFlags) commit(message string) int {
+	summary, err := git.Commit(git.CommitOptions{
+		Message:  message + "\n",
+		Amend:    *f.amend,
+		Signoff:  *f.signoff,
+		Sign:     *f.sign,
+		SignKey:  *f.signKey,
+		NoSign:   *f.noSign,
+		Author:   *f.author,
+		NoVerify: *f.noVerify,
+	})
+	if err != nil {
+		return runtimeError(err)
+	}
+	fmt.Println(summary)
+	return exitOK
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"git-commit/internal/branch"
+	"git-commit/internal/config"
+	"git-commit/internal/diff"
+	"git-commit/internal/git"
+	"git-commit/internal/output"
+	"git-commit/internal/parser"
+	"git-commit/internal/prompt"
+	"git-commit/internal/provider"
+)
+
+// providerFlags holds the flags shared by commands that call a model provider
+type providerFlags struct {
+	name    *string
+	model   *string
+	baseURL *string
+	timeout *time.Duration
+}
+
+// addProviderFlags registers the provider flags on fs, defaulting to GIT_COMMIT_* environment variables
+// and then to the provider settings of config.yaml
+func addProviderFlags(fs *flag.FlagSet) providerFlags {
+	cfg := settings.Provider
+	timeout := cfg.Timeout
+	if timeout == 0 {
+		timeout = provider.DefaultTimeout
+	}
+	return providerFlags{
+		name:    fs.String("provider", envOr("GIT_COMMIT_PROVIDER", cfg.Name), "model provider: "+strings.Join(provider.Names(), ", ")),
+		model:   fs.String("model", envOr("GIT_COMMIT_MODEL", cfg.Model), "model name, provider default when empty"),
+		baseURL: fs.String("base-url", envOr("GIT_COMMIT_BASE_URL", cfg.BaseURL), "API base URL, provider default when empty"),
+		timeout: fs.Duration("timeout", timeout, "request timeout"),
+	}
+}
+
+// newProvider creates the provider selected by the flags
+func (f providerFlags) newProvider() (provider.Provider, error) {
+	return provider.New(provider.Config{
+		Name:      *f.name,
+		Model:     *f.model,
+		BaseURL:   *f.baseURL,
+		MaxTokens: settings.Provider.MaxTokens,
+		Timeout:   *f.timeout,
+	})
+}
+
+// providerName returns the provider configured through GIT_COMMIT_PROVIDER or config.yaml, or ""
+func providerName() string {
+	return envOr("GIT_COMMIT_PROVIDER", settings.Provider.Name)
+}
+
+// providerFromEnv creates the provider configured through the GIT_COMMIT_* environment variables,
+// falling back to the provider settings of config.yaml
+func providerFromEnv() (provider.Provider, error) {
+	return provider.New(provider.Config{
+		Name:      providerName(),
+		Model:     envOr("GIT_COMMIT_MODEL", settings.Provider.Model),
+		BaseURL:   envOr("GIT_COMMIT_BASE_URL", settings.Provider.BaseURL),
+		MaxTokens: settings.Provider.MaxTokens,
+		Timeout:   settings.Provider.Timeout,
+	})
+}
+
+// envOr returns the value of the environment variable key, or fallback when it is empty
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// flagSet reports whether the flag name was given on the command line
+func flagSet(fs *flag.FlagSet, name string) bool {
+	found := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			found = true
+		}
+	})
+	return found
+}
+
+// buildRequest assembles the provider request for the default or a custom prompt of repo describing src
+func buildRequest(repo *git.Repo, promptName string, src diff.Source) (provider.Request, error) {
+	promptName = prompt.PromptName(repo, promptName)
+	if promptName != "" {
+		// Custom prompts embed the diff themselves through the @diff directive
+		text, err := prompt.GetAIPrompt(repo, promptName, src)
+		return provider.Request{Prompt: text}, err
+	}
+	rawPrompt, err := prompt.GetChangesAiPrompt(repo)
+	if err != nil {
+		return provider.Request{}, err
+	}
+	diffOutput, err := diff.GetDiffOutput(repo, src)
+	return provider.Request{
+		Prompt: rawPrompt,
+		Diff:   diffOutput,
+	}, err
+}
+
+// runGenerate sends the prompt for the staged changes to a model provider and prints the answer,
+// or commits it with -commit
+func runGenerate(args []string) int {
+	fs := newFlagSet("generate")
+	providerOpts := addProviderFlags(fs)
+	src := addSourceFlag(fs)
+	copyResult := fs.Bool("copy", false, "also copy the generated text to the clipboard")
+	outputSpec := addOutputFlag(fs, config.OutputStdout)
+	parseResult := fs.Bool("parse", false, "parse the answer into a branch name and commit message, failing if it does not match the format")
+	checkout := fs.Bool("checkout", false, "create and switch to the generated branch after validating it (implies -parse)")
+	assumeYes := fs.Bool("yes", false, "do not ask for confirmation before creating the branch")
+	commitResult := fs.Bool("commit", false, "commit the staged changes with the generated message")
+	commitOpts := addCommitFlags(fs)
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return flagError(err)
+	}
+	if len(positional) > 1 {
+		return usageError("generate accepts at most one prompt name, got %d", len(positional))
+	}
+	for _, name := range commitFlagNames {
+		if flagSet(fs, name) && !*commitResult {
+			return usageError("-%s needs -commit", name)
+		}
+	}
+	if err := commitOpts.check(); err != nil {
+		return usageError("%v", err)
+	}
+	if *commitResult && flagSet(fs, "diff") {
+		return usageError("-commit describes the staged changes, it cannot be combined with -diff")
+	}
+
+	var promptName string
+	if len(positional) == 1 {
+		promptName = positional[0]
+	}
+	repo := openRepo()
+	promptName = prompt.PromptName(repo, promptName)
+	if promptName != "" && !hasCustomPrompt(repo, promptName) {
+		return usageError("custom prompt %q not found in .git-commit/custom-instructions", promptName)
+	}
+	if promptName != "" {
+		meta, err := prompt.Metadata(repo, promptName)
+		if err != nil {
+			return usageError("custom prompt %q: %v", promptName, err)
+		}
+		// The prompt's preferred model beats the environment and config.yaml, but not -model
+		if meta.Model != "" && !flagSet(fs, "model") {
+			*providerOpts.model = meta.Model
+		}
+		if (*parseResult || *checkout) && meta.Output != "" && meta.Output != prompt.OutputBranchCommit {
+			return usageError("custom prompt %q answers with %s output, -parse and -checkout need %s", promptName, meta.Output, prompt.OutputBranchCommit)
+		}
+	}
+
+	p, err := providerOpts.newProvider()
+	if err != nil {
+		return usageError("%v", err)
+	}
+	if repo == nil {
+		return notARepository()
+	}
+	if *src, err = commitOpts.source(*src); err != nil {
+		return runtimeError(err)
+	}
+
+	req, err := buildRequest(repo, promptName, *src)
+	if err != nil {
+		return runtimeError(err)
+	}
+	debugf("sending %d bytes to %s", len(req.Prompt)+len(req.Diff), p.Name())
+	result, err := p.Generate(context.Background(), req)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "git-commit: %v\n", err)
+		return exitError
+	}
+
+	result = strings.TrimSpace(result)
+	out := output.Result{Kind: output.KindMessage, Prompt: promptName, Source: src.String(), Text: result}
+	parsed, err := parser.Parse(result)
+	if err == nil {
+		out.Branch, out.Commit = parsed.Branch, strings.TrimSpace(parsed.Commit.String())
+	}
+	if *parseResult || *checkout {
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "git-commit: model answer does not match the expected format: %v\n", err)
+			return exitError
+		}
+		out.Text = fmt.Sprintf("Branch: %s\n\n%s", out.Branch, out.Commit)
+	}
+	if *commitResult {
+		// Never commit prose: the answer must hold a Conventional Commits message, with or without a branch
+		answer, err := parser.ParseAnswer(result)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "git-commit: model answer is not a commit message, nothing committed: %v\n", err)
+			return exitInvalid
+		}
+		out.Branch, out.Commit = answer.Branch, strings.TrimSpace(answer.Commit.String())
+	}
+	// With -commit, the message is only delivered elsewhere when -output asks for it
+	if !*commitResult || flagSet(fs, "output") {
+		if code := writeOutput(*outputSpec, out); code != exitOK {
+			return code
+		}
+	}
+	if *copyResult && *outputSpec != config.OutputClipboard {
+		if code := writeOutput(config.OutputClipboard, out); code != exitOK {
+			return code
+		}
+	}
+
+	if *checkout {
+		if err := branch.Validate(parsed.Branch); err != nil {
+			fmt.Fprintf(os.Stderr, "git-commit: %v\n", err)
+			return exitInvalid
+		}
+		if code := checkoutBranch(parsed.Branch, *assumeYes); code != exitOK || !*commitResult {
+			return code
+		}
+	}
+	if *commitResult {
+		return commitOpts.commit(out.Commit)
+	}
+	return exitOK
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"git-commit/internal/diff"
+	"git-commit/internal/hooks"
+	"git-commit/internal/lint"
+	"git-commit/internal/parser"
+)
+
+// runHooks installs, uninstalls or reports the git hooks managed by git-commit
+func runHooks(args []string) int {
+	fs := newFlagSet("hooks")
+	force := fs.Bool("force", false, "overwrite existing hooks instead of chaining them")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return flagError(err)
+	}
+	if len(positional) != 1 {
+		return usageError("hooks expects one of: install, uninstall, status")
+	}
+
+	repo := openRepo()
+	if repo == nil {
+		return notARepository()
+	}
+	dir, err := hooks.Dir(repo)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "git-commit: %v\n", err)
+		return exitError
+	}
+	debugf("hooks directory: %s", dir)
+
+	var statuses []hooks.Status
+	switch positional[0] {
+	case "install":
+		var binary string
+		binary, err = os.Executable()
+		if err == nil {
+			binary, err = filepath.EvalSymlinks(binary)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "git-commit: failed to locate the git-commit binary: %v\n", err)
+			return exitError
+		}
+		statuses, err = hooks.Install(dir, binary, *force)
+	case "uninstall":
+		statuses, err = hooks.Uninstall(dir)
+	case "status":
+		statuses, err = hooks.List(dir)
+	default:
+		return usageError("unknown hooks action %q, expected install, uninstall or status", positional[0])
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "git-commit: %v\n", err)
+		return exitError
+	}
+
+	for _, status := range statuses {
+		state := "not installed"
+		switch {
+		case status.Installed && status.Chained:
+			state = "installed, runs existing hook first"
+		case status.Installed:
+			state = "installed"
+		case status.Foreign:
+			state = "managed by another tool"
+		}
+		fmt.Printf("%-20s %-36s %s\n", status.Name, state, status.Path)
+	}
+	return exitOK
+}
+
+// runHook is invoked by the installed hook scripts with the arguments git passes to the hook
+func runHook(args []string) int {
+	if len(args) < 2 {
+		return usageError("hook expects a hook name and the message file")
+	}
+
+	switch args[0] {
+	case "commit-msg":
+		// Messages git writes for merges, reverts and autosquash commits are not linted
+		if message, err := readMessage(args[1]); err == nil && lint.IsGenerated(lint.StripComments(message)) {
+			debugf("skipping commit-msg for a message generated by git")
+			return exitOK
+		}
+		return runLint(args[1:2])
+	case "prepare-commit-msg":
+		prepareCommitMessage(args[1], args[2:])
+		// Never block a commit because generation failed
+		return exitOK
+	}
+	return usageError("unsupported hook %q", args[0])
+}
+
+// prepareCommitMessage pre-fills the commit message file with a generated message.
+// It only runs for plain "git commit" invocations and when a provider is configured.
+func prepareCommitMessage(messageFile string, extra []string) {
+	// A source means the message comes from -m, -F, a template, a merge, a squash or an amend
+	if len(extra) > 0 && extra[0] != "" {
+		debugf("skipping prepare-commit-msg for source %q", extra[0])
+		return
+	}
+	if providerName() == "" {
+		debugf("skipping prepare-commit-msg, no provider is configured")
+		return
+	}
+
+	repo := openRepo()
+	if repo == nil {
+		return
+	}
+
+	req, err := buildRequest(repo, "", diff.Source{Kind: diff.SourceStaged})
+	if errors.Is(err, diff.ErrNoChanges) {
+		// Nothing is staged or every staged file is ignored, there is nothing to describe
+		return
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "git-commit: could not read the staged changes: %v\n", err)
+		return
+	}
+
+	p, err := providerFromEnv()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "git-commit: %v\n", err)
+		return
+	}
+
+	result, err := p.Generate(context.Background(), req)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "git-commit: could not generate commit message: %v\n", err)
+		return
+	}
+	parsed, err := parser.ParseAnswer(strings.TrimSpace(result))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "git-commit: model answer is not a commit message, not using it: %v\n", err)
+		return
+	}
+	message := parsed.Commit.String()
+
+	template, err := os.ReadFile(messageFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "git-commit: %v\n", err)
+		return
+	}
+	if err := os.WriteFile(messageFile, []byte(message+string(template)), 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "git-commit: %v\n", err)
+	}
+}
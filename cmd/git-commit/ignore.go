@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"git-commit/internal/git"
+)
+
+// runCheckIgnore reports which ignore rule decides whether each path is left out of the prompt
+func runCheckIgnore(args []string) int {
+	fs := newFlagSet("check-ignore")
+	nonMatching := fs.Bool("n", false, "also show paths that no rule matches")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return flagError(err)
+	}
+	if len(positional) == 0 {
+		return usageError("check-ignore expects at least one path")
+	}
+
+	repo := openRepo()
+	if repo == nil {
+		return notARepository()
+	}
+
+	paths := make([]string, len(positional))
+	for i, arg := range positional {
+		if paths[i], err = repo.Rel(arg); err != nil {
+			fmt.Fprintf(os.Stderr, "git-commit: %v\n", err)
+			return exitUsage
+		}
+	}
+
+	matcher, err := git.LoadIgnore(repo, paths)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "git-commit: %v\n", err)
+		return exitError
+	}
+
+	for i, path := range paths {
+		isDir := strings.HasSuffix(positional[i], "/")
+		if info, err := os.Stat(repo.Path(path)); err == nil && info.IsDir() {
+			isDir = true
+		}
+
+		pattern := matcher.Explain(path, isDir)
+		switch {
+		case pattern != nil:
+			// Same layout as "git check-ignore -v": source:line:pattern<TAB>path
+			fmt.Printf("%s:%d:%s\t%s\n", pattern.Source, pattern.Line, pattern.Raw, positional[i])
+		case *nonMatching:
+			fmt.Printf("::\t%s\n", positional[i])
+		}
+	}
+	return exitOK
+}
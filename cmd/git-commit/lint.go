@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"git-commit/internal/git"
+	"git-commit/internal/lint"
+)
+
+// runLint checks a commit message from a file, stdin ("-") or .git/COMMIT_EDITMSG against the lint rules
+func runLint(args []string) int {
+	fs := newFlagSet("lint")
+	listRules := fs.Bool("rules", false, "list the available rules and their configured severity")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return flagError(err)
+	}
+	if len(positional) > 1 {
+		return usageError("lint accepts at most one message file, got %d", len(positional))
+	}
+
+	// Outside a repository the rule overrides are looked up in the current directory
+	cfg, err := lint.LoadConfig(openRepo().Path(lint.ConfigPath))
+	if err != nil {
+		return usageError("%v", err)
+	}
+	// Rules from config.yaml were validated when it was loaded and take precedence
+	for id, setting := range settings.Lint {
+		if err := cfg.Set(id, setting); err != nil {
+			return usageError("%v", err)
+		}
+	}
+
+	if *listRules {
+		for _, r := range lint.Rules() {
+			ruleCfg := cfg[r[0]]
+			setting := string(ruleCfg.Severity)
+			if ruleCfg.Value != "" {
+				setting += " " + ruleCfg.Value
+			}
+			fmt.Printf("%-24s %-28s %s\n", r[0], setting, r[1])
+		}
+		return exitOK
+	}
+
+	source := "-"
+	if len(positional) == 1 {
+		source = positional[0]
+	} else if !isPiped(os.Stdin) {
+		source, err = git.GitPath("COMMIT_EDITMSG")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "git-commit: %v\n", err)
+			return exitError
+		}
+	}
+
+	message, err := readMessage(source)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "git-commit: %v\n", err)
+		return exitError
+	}
+
+	issues := lint.Lint(lint.StripComments(message), cfg)
+	name := source
+	if name == "-" {
+		name = "<stdin>"
+	}
+	for _, issue := range issues {
+		fmt.Fprintf(os.Stderr, "%s:%s\n", name, issue)
+	}
+
+	if lint.HasErrors(issues) {
+		return exitInvalid
+	}
+	return exitOK
+}
+
+// readMessage reads a commit message from a file or, for "-", from stdin
+func readMessage(source string) (string, error) {
+	if source == "-" {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return "", fmt.Errorf("failed to read message from stdin: %v", err)
+		}
+		return string(data), nil
+	}
+
+	data, err := os.ReadFile(source)
+	if err != nil {
+		return "", fmt.Errorf("failed to read message file: %v", err)
+	}
+	return string(data), nil
+}
+
+// isPiped reports whether f is a pipe or file rather than a terminal
+func isPiped(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice == 0
+}
@@ -0,0 +1,223 @@
+// Command git-commit generates AI prompts for staged git changes.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"git-commit/internal/clipboard"
+	"git-commit/internal/config"
+	"git-commit/internal/git"
+	"git-commit/internal/help"
+)
+
+// Exit codes returned by git-commit so scripts can tell failures apart
+const (
+	exitOK      = 0 // command succeeded
+	exitError   = 1 // command failed at runtime
+	exitUsage   = 2 // invalid flags, arguments or unknown prompt name
+	exitInvalid = 3 // commit message or branch name violates a rule with error severity
+)
+
+// command is a single git-commit subcommand
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) int
+}
+
+// commands lists the available subcommands in the order they are shown in help
+var commands = []command{
+	{"prompt", "Generate AI prompt with git diff and copy to clipboard", runPrompt},
+	{"generate", "Generate the commit message with a model provider", runGenerate},
+	{"review", "Review, edit or regenerate the generated message and commit it", runReview},
+	{"branch", "Validate a branch name and optionally create and switch to it", runBranch},
+	{"hooks", "Install, uninstall or show the prepare-commit-msg and commit-msg hooks", runHooks},
+	{"hook", "Run a git hook (used by the installed hook scripts)", runHook},
+	{"lint", "Check a commit message against the Conventional Commits rules", runLint},
+	{"check-ignore", "Show which ignore rule excludes each path from the prompt", runCheckIgnore},
+	{"list", "List available custom prompts", runList},
+	{"show", "Print a custom prompt (or the default prompt) without processing it", runShow},
+	{"init", "Create the .git-commit configuration folder", runInit},
+	{"doctor", "Check the environment git-commit depends on", runDoctor},
+}
+
+// verbose enables diagnostic logging to stderr
+var verbose bool
+
+// settings is the merged config.yaml configuration, loaded before a command runs
+var settings = config.Default()
+
+// current caches the repository found by openRepo
+var current struct {
+	opened bool
+	repo   *git.Repo
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("git-commit: ")
+	os.Exit(run(os.Args[1:]))
+}
+
+// run parses global flags, dispatches to a subcommand and returns the process exit code
+func run(args []string) int {
+	global := flag.NewFlagSet("git-commit", flag.ContinueOnError)
+	global.SetOutput(io.Discard)
+	showHelp := global.Bool("h", false, "show help")
+	global.BoolVar(showHelp, "help", false, "show help")
+	global.BoolVar(&verbose, "v", false, "enable verbose output")
+	generateOnly := global.Bool("generate-prompt", false, "print the prompt instead of copying it")
+
+	if err := global.Parse(args); err != nil {
+		fmt.Fprintf(os.Stderr, "git-commit: %v\n", err)
+		fmt.Fprintln(os.Stderr, "Run 'git-commit -h' for usage.")
+		return exitUsage
+	}
+	if *showHelp {
+		help.ShowHelp(openRepo())
+		return exitOK
+	}
+
+	rest := global.Args()
+	if len(rest) > 0 && rest[0] == "help" {
+		help.ShowHelp(openRepo())
+		return exitOK
+	}
+
+	// Without a known subcommand, behave like "git-commit prompt [prompt-name]"
+	name := "prompt"
+	if len(rest) > 0 {
+		if _, ok := findCommand(rest[0]); ok {
+			name, rest = rest[0], rest[1:]
+		}
+	}
+	if *generateOnly {
+		if name != "prompt" {
+			fmt.Fprintln(os.Stderr, "git-commit: -generate-prompt can only be used with the prompt command")
+			return exitUsage
+		}
+		rest = append([]string{"-print"}, rest...)
+	}
+
+	// doctor reports configuration errors itself
+	if err := loadSettings(); err != nil && name != "doctor" {
+		fmt.Fprintf(os.Stderr, "git-commit: %v\n", err)
+		return exitUsage
+	}
+
+	cmd, _ := findCommand(name)
+	debugf("running command %q with args %q", cmd.name, rest)
+	return cmd.run(rest)
+}
+
+// findCommand looks up a subcommand by name
+func findCommand(name string) (command, bool) {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd, true
+		}
+	}
+	return command{}, false
+}
+
+// openRepo discovers the repository containing the working directory, or returns nil outside one
+func openRepo() *git.Repo {
+	if current.opened {
+		return current.repo
+	}
+	current.opened = true
+
+	r, err := git.OpenRepo("")
+	if err != nil {
+		debugf("%v", err)
+		return nil
+	}
+	debugf("repository root: %s", r.Root)
+	r.Config = settings
+	current.repo = r
+	return r
+}
+
+// loadSettings merges the global and repository config.yaml files and applies the settings
+// that are not tied to a single command
+func loadSettings() error {
+	root := ""
+	if r := openRepo(); r != nil {
+		root = r.Root
+	}
+	cfg, err := config.Load(root)
+	if err != nil {
+		return err
+	}
+
+	settings = cfg
+	if r := openRepo(); r != nil {
+		r.Config = cfg
+	}
+	return clipboard.Configure(cfg.Clipboard)
+}
+
+// notARepository reports that the command must run inside a git working tree
+func notARepository() int {
+	fmt.Fprintln(os.Stderr, "git-commit: not a git repository")
+	return exitError
+}
+
+// parseArgs parses fs from args, allowing flags to follow positional arguments
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// newFlagSet creates a flag set for a subcommand that reports errors to stderr
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet("git-commit "+name, flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	return fs
+}
+
+// usageError reports a usage problem and returns exitUsage
+func usageError(format string, a ...interface{}) int {
+	fmt.Fprintf(os.Stderr, "git-commit: "+format+"\n", a...)
+	return exitUsage
+}
+
+// runtimeError reports an error of a command and returns exitError.
+// Errors outside a repository are reported like notARepository.
+func runtimeError(err error) int {
+	if errors.Is(err, git.ErrNotARepository) {
+		return notARepository()
+	}
+	fmt.Fprintf(os.Stderr, "git-commit: %v\n", err)
+	return exitError
+}
+
+// flagError converts a flag parsing error into an exit code
+func flagError(err error) int {
+	if errors.Is(err, flag.ErrHelp) {
+		return exitOK
+	}
+	return exitUsage
+}
+
+// debugf logs a diagnostic message when verbose output is enabled
+func debugf(format string, a ...interface{}) {
+	if verbose {
+		log.Printf(format, a...)
+	}
+}
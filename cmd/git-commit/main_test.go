@@ -0,0 +1,87 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFindCommand(t *testing.T) {
+	for _, name := range []string{"prompt", "generate", "review", "branch", "lint", "check-ignore", "list", "show", "init", "doctor"} {
+		if _, ok := findCommand(name); !ok {
+			t.Errorf("findCommand(%q) not found", name)
+		}
+	}
+
+	if _, ok := findCommand("mark"); ok {
+		t.Errorf("findCommand(%q) should not match a prompt name", "mark")
+	}
+}
+
+func TestParseArgsInterspersedFlags(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	printOnly := fs.Bool("print", false, "")
+
+	positional, err := parseArgs(fs, []string{"mark", "-print"})
+	if err != nil {
+		t.Fatalf("parseArgs() error = %v", err)
+	}
+
+	if !*printOnly {
+		t.Error("Expected -print after positional argument to be parsed")
+	}
+	if len(positional) != 1 || positional[0] != "mark" {
+		t.Errorf("Expected positional [mark], got %q", positional)
+	}
+}
+
+func TestRunExitCodes(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{"Help flag", []string{"-h"}, exitOK},
+		{"Help command", []string{"help"}, exitOK},
+		{"Unknown global flag", []string{"-unknown"}, exitUsage},
+		{"Generate prompt with other command", []string{"-generate-prompt", "list"}, exitUsage},
+		{"Too many list arguments", []string{"list", "extra"}, exitUsage},
+		{"Commit flag without -commit", []string{"generate", "-signoff"}, exitUsage},
+		{"Conflicting signing flags", []string{"review", "-sign", "-no-sign"}, exitUsage},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := run(tt.args); got != tt.want {
+				t.Errorf("run(%q) = %d; want %d", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommitMsgHookSkipsGeneratedMessages(t *testing.T) {
+	tests := []struct {
+		message string
+		want    int
+	}{
+		{"Merge branch 'feature/login'\n", exitOK},
+		{"Revert \"feat: add login\"\n\nThis reverts commit 0123456789abcdef.\n", exitOK},
+		{"fixup! feat: add login\n", exitOK},
+		{"squash! feat: add login\n", exitOK},
+		{"amend! feat: add login\n\nfeat: add the login form\n", exitOK},
+		{"# Please enter the commit message\nMerge branch 'main' into feature/login\n", exitOK},
+		{"feat: add login\n", exitOK},
+		{"Add login\n", exitInvalid},
+	}
+
+	for _, tt := range tests {
+		file := filepath.Join(t.TempDir(), "COMMIT_EDITMSG")
+		if err := os.WriteFile(file, []byte(tt.message), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if got := run([]string{"hook", "commit-msg", file}); got != tt.want {
+			t.Errorf("commit-msg hook on %q = %d; want %d", tt.message, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"git-commit/internal/branch"
+	"git-commit/internal/diff"
+	"git-commit/internal/git"
+	"git-commit/internal/help"
+	"git-commit/internal/output"
+	"git-commit/internal/prompt"
+	"git-commit/internal/provider"
+	"git-commit/internal/review"
+)
+
+// runReview generates a message for the staged changes, lets the user accept, edit or regenerate
+// it, and commits the accepted message
+func runReview(args []string) int {
+	fs := newFlagSet("review")
+	providerOpts := addProviderFlags(fs)
+	checkout := fs.Bool("checkout", false, "create and switch to the generated branch before committing")
+	commitOpts := addCommitFlags(fs)
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return flagError(err)
+	}
+	if err := commitOpts.check(); err != nil {
+		return usageError("%v", err)
+	}
+	if len(positional) > 1 {
+		return usageError("review accepts at most one prompt name, got %d", len(positional))
+	}
+
+	var promptName string
+	if len(positional) == 1 {
+		promptName = positional[0]
+	}
+	repo := openRepo()
+	promptName = prompt.PromptName(repo, promptName)
+	if promptName != "" && !hasCustomPrompt(repo, promptName) {
+		return usageError("custom prompt %q not found in .git-commit/custom-instructions", promptName)
+	}
+	if promptName != "" {
+		meta, err := prompt.Metadata(repo, promptName)
+		if err != nil {
+			return usageError("custom prompt %q: %v", promptName, err)
+		}
+		if meta.Model != "" && !flagSet(fs, "model") {
+			*providerOpts.model = meta.Model
+		}
+	}
+
+	p, err := providerOpts.newProvider()
+	if err != nil {
+		return usageError("%v", err)
+	}
+	if repo == nil {
+		return notARepository()
+	}
+
+	// The summary lists every committed file, including the ignored ones
+	src, err := commitOpts.source(diff.Source{Kind: diff.SourceStaged})
+	if err != nil {
+		return runtimeError(err)
+	}
+	diffArgs := []string{"diff", "--staged"}
+	if src.Rev != "" {
+		diffArgs = append(diffArgs, src.Rev)
+	}
+	staged, err := git.GetDiff(diffArgs, nil, 0)
+	if err != nil {
+		return runtimeError(err)
+	}
+	summary := diff.Parse(staged)
+	if len(summary.Files) == 0 {
+		return runtimeError(diff.ErrNoStagedChanges)
+	}
+
+	session := &review.Session{
+		In:       os.Stdin,
+		Out:      os.Stdout,
+		Summary:  summary.Summary(),
+		Prompt:   promptName,
+		Prompts:  help.GetAvailableCustomPrompts(repo),
+		Provider: p,
+		Request: func(name string) (provider.Request, error) {
+			return buildRequest(repo, name, src)
+		},
+		Edit: output.Edit,
+	}
+	if *checkout {
+		// Reject an invalid branch name while the user can still regenerate or abort
+		session.Check = func(p *review.Proposal) error {
+			if p.Branch == "" {
+				return nil
+			}
+			return branch.Validate(p.Branch)
+		}
+	}
+	proposal, err := session.Run(context.Background())
+	if errors.Is(err, review.ErrAborted) {
+		fmt.Fprintln(os.Stderr, "Nothing committed.")
+		return exitError
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "git-commit: %v\n", err)
+		return exitError
+	}
+
+	if *checkout && proposal.Branch != "" {
+		if current, _ := git.CurrentBranch(); current != proposal.Branch {
+			if err := git.CreateBranch(proposal.Branch); err != nil {
+				return runtimeError(err)
+			}
+			fmt.Printf("Switched to a new branch '%s'.\n", proposal.Branch)
+		}
+	}
+	return commitOpts.commit(proposal.Message)
+}
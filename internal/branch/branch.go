@@ -0,0 +1,118 @@
+package branch
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Prefixes are the branch prefixes declared by the default prompt
+var Prefixes = []string{"feature", "bugfix", "hotfix", "docs", "refactor", "test", "chore"}
+
+// ValidationError lists every rule a branch name violates
+type ValidationError struct {
+	Name     string
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid branch name '%s': %s", e.Name, strings.Join(e.Problems, "; "))
+}
+
+var (
+	ticketPattern = regexp.MustCompile(`^([A-Z][A-Z0-9]*-[0-9]+)(?:-|$)`)
+	wordsPattern  = regexp.MustCompile(`^[a-z0-9]+(?:-[a-z0-9]+)*$`)
+)
+
+// Validate checks a branch name against the naming rules of the default prompt:
+// "<prefix>/[TICKET-123-]lowercase-hyphenated-words"
+func Validate(name string) error {
+	var problems []string
+
+	prefix, rest, found := strings.Cut(name, "/")
+	switch {
+	case strings.TrimSpace(name) == "":
+		problems = append(problems, "name is empty")
+	case !found:
+		problems = append(problems, fmt.Sprintf("missing prefix, start with one of: %s", prefixList()))
+	case !isPrefix(prefix):
+		problems = append(problems, fmt.Sprintf("unknown prefix '%s/', use one of: %s", prefix, prefixList()))
+	}
+
+	if found {
+		description := strings.TrimPrefix(rest, Ticket(name))
+		description = strings.TrimPrefix(description, "-")
+		problems = append(problems, descriptionProblems(rest, description)...)
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Name: name, Problems: problems}
+	}
+	return nil
+}
+
+// Ticket returns the ticket ID following the prefix, e.g. "T-123" for "feature/T-123-add-filters"
+func Ticket(name string) string {
+	_, rest, found := strings.Cut(name, "/")
+	if !found {
+		return ""
+	}
+	match := ticketPattern.FindStringSubmatch(rest)
+	if match == nil {
+		return ""
+	}
+	return match[1]
+}
+
+// descriptionProblems checks the part after the prefix and optional ticket ID and reports every
+// rule it breaks
+func descriptionProblems(rest, description string) []string {
+	if description == "" {
+		return []string{"missing description after the prefix"}
+	}
+
+	var problems []string
+	if strings.Contains(description, "/") {
+		problems = append(problems, "only one '/' is allowed, after the prefix")
+	}
+	if strings.Contains(description, "--") {
+		problems = append(problems, "repeated hyphens are not allowed")
+	}
+	if strings.HasPrefix(description, "-") || strings.HasSuffix(rest, "-") {
+		problems = append(problems, "must not start or end with a hyphen")
+	}
+	if strings.Contains(description, "_") {
+		problems = append(problems, "use hyphens instead of underscores (snake_case)")
+	}
+	if strings.Contains(description, ".") {
+		problems = append(problems, "dots are not allowed")
+	}
+	if strings.ToLower(description) != description {
+		problems = append(problems, "use lowercase words (no PascalCase or camelCase)")
+	}
+	// The general rule only explains what is left, e.g. spaces or other punctuation
+	if len(problems) == 0 && !wordsPattern.MatchString(description) {
+		problems = append(problems, "only lowercase letters, digits and single hyphens are allowed")
+	}
+
+	return problems
+}
+
+// isPrefix reports whether prefix is one of the allowed prefixes
+func isPrefix(prefix string) bool {
+	for _, allowed := range Prefixes {
+		if prefix == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// prefixList formats the allowed prefixes for error messages
+func prefixList() string {
+	var prefixes []string
+	for _, prefix := range Prefixes {
+		prefixes = append(prefixes, prefix+"/")
+	}
+	return strings.Join(prefixes, ", ")
+}
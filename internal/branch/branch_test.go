@@ -0,0 +1,83 @@
+package branch
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		branch  string
+		wantErr bool
+	}{
+		{"Feature", "feature/add-user-auth", false},
+		{"Bugfix with ticket", "bugfix/T-456-fix-login-bug", false},
+		{"Docs single word", "docs/readme", false},
+		{"Digits", "chore/bump-go-1-24", false},
+		{"Empty", "", true},
+		{"No prefix", "add-user-auth", true},
+		{"Unknown prefix", "feat/add-user-auth", true},
+		{"Missing description", "feature/", true},
+		{"Ticket only", "feature/T-123", true},
+		{"Uppercase", "feature/AddUserAuth", true},
+		{"Camel case", "feature/addUserAuth", true},
+		{"Snake case", "feature/add_user_auth", true},
+		{"Dots", "feature/v1.2-release", true},
+		{"Repeated hyphens", "feature/add--auth", true},
+		{"Trailing hyphen", "feature/add-auth-", true},
+		{"Nested slash", "feature/auth/login", true},
+		{"Special characters", "feature/add-auth!", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.branch)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate(%q) error = %v; wantErr %v", tt.branch, err, tt.wantErr)
+			}
+			if err != nil {
+				var validationErr *ValidationError
+				if !errors.As(err, &validationErr) || len(validationErr.Problems) == 0 {
+					t.Errorf("Expected *ValidationError with problems, got %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateReportsEveryProblem(t *testing.T) {
+	err := Validate("feat/Foo_bar.baz")
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("Validate() error = %v; want *ValidationError", err)
+	}
+	expected := []string{
+		"unknown prefix 'feat/', use one of: " + prefixList(),
+		"use hyphens instead of underscores (snake_case)",
+		"dots are not allowed",
+		"use lowercase words (no PascalCase or camelCase)",
+	}
+	if strings.Join(validationErr.Problems, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Validate() problems = %q; want %q", validationErr.Problems, expected)
+	}
+}
+
+func TestTicket(t *testing.T) {
+	tests := []struct {
+		branch   string
+		expected string
+	}{
+		{"feature/T-123-add-filters", "T-123"},
+		{"bugfix/JIRA-42-fix-crash", "JIRA-42"},
+		{"feature/add-filters", ""},
+		{"add-filters", ""},
+	}
+
+	for _, tt := range tests {
+		if got := Ticket(tt.branch); got != tt.expected {
+			t.Errorf("Ticket(%q) = %q; want %q", tt.branch, got, tt.expected)
+		}
+	}
+}
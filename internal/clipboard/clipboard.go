@@ -0,0 +1,212 @@
+// Package clipboard copies text to the system clipboard. It probes the backends that work in
+// the current session in order, Wayland, WSL, tmux, the native utilities and finally OSC 52
+// escape sequences over SSH, unless the configuration forces one.
+package clipboard
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// Names of the backends accepted by Configure besides the command backends
+const (
+	Auto  = "auto"  // probe for a backend that works in this session
+	None  = "none"  // never copy, callers print the text instead
+	OSC52 = "osc52" // OSC 52 escape sequence written to the terminal
+)
+
+// ErrUnavailable is returned by Copy when no backend works in this session
+var ErrUnavailable = errors.New("no clipboard backend available")
+
+// Backend copies text to a clipboard
+type Backend interface {
+	Name() string
+	Copy(text string) error
+}
+
+// env is what probing looks at, replaced in tests
+type env struct {
+	goos     string
+	getenv   func(string) string
+	lookPath func(string) (string, error)
+	isWSL    func() bool
+}
+
+// system is the environment of the running process
+var system = env{goos: runtime.GOOS, getenv: os.Getenv, lookPath: exec.LookPath, isWSL: isWSL}
+
+// command is a backend running a clipboard utility with the text on stdin
+type command struct {
+	name   string
+	args   []string
+	usable func(e env) bool // whether the session supports the utility, besides it being installed
+}
+
+func (c *command) Name() string { return c.name }
+
+func (c *command) Copy(text string) error {
+	cmd := exec.Command(c.args[0], c.args[1:]...)
+	cmd.Stdin = strings.NewReader(text)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s failed: %v %s", c.name, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// osc52 writes the text as an OSC 52 escape sequence, which terminals such as iTerm2, kitty,
+// WezTerm and Windows Terminal turn into a clipboard update, even across SSH
+type osc52 struct {
+	tmux bool // wrap the sequence so tmux passes it through to the outer terminal
+}
+
+func (o *osc52) Name() string { return OSC52 }
+
+func (o *osc52) Copy(text string) error {
+	tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+	if err != nil {
+		return fmt.Errorf("osc52 needs a terminal: %v", err)
+	}
+	defer tty.Close()
+	return o.write(tty, text)
+}
+
+// write writes the escape sequence for text to w
+func (o *osc52) write(w io.Writer, text string) error {
+	seq := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(text)) + "\a"
+	if o.tmux {
+		seq = "\x1bPtmux;" + strings.ReplaceAll(seq, "\x1b", "\x1b\x1b") + "\x1b\\"
+	}
+	_, err := io.WriteString(w, seq)
+	return err
+}
+
+// backends lists the command backends in probing order
+var backends = []*command{
+	{name: "wl-copy", args: []string{"wl-copy"}, usable: func(e env) bool { return e.getenv("WAYLAND_DISPLAY") != "" }},
+	{name: "clip.exe", args: []string{"clip.exe"}, usable: func(e env) bool { return e.goos == "linux" && e.isWSL() }},
+	// -w also sets the terminal clipboard through tmux's set-clipboard option, tmux 3.2 or later
+	{name: "tmux", args: []string{"tmux", "load-buffer", "-w", "-"}, usable: func(e env) bool { return e.getenv("TMUX") != "" }},
+	{name: "pbcopy", args: []string{"pbcopy"}, usable: func(e env) bool { return e.goos == "darwin" }},
+	{name: "clip", args: []string{"clip"}, usable: func(e env) bool { return e.goos == "windows" }},
+	{name: "xclip", args: []string{"xclip", "-selection", "clipboard"}, usable: func(e env) bool { return e.getenv("DISPLAY") != "" }},
+	{name: "xsel", args: []string{"xsel", "--clipboard", "--input"}, usable: func(e env) bool { return e.getenv("DISPLAY") != "" }},
+}
+
+// Names returns the values accepted by Configure
+func Names() []string {
+	names := []string{Auto, None}
+	for _, b := range backends {
+		names = append(names, b.name)
+	}
+	return append(names, OSC52)
+}
+
+// selected is the configured backend name, override a backend installed by Use
+var (
+	selected = Auto
+	override Backend
+)
+
+// Configure selects the backend used by Copy: Auto probes for one, None disables copying and
+// any other name of Names forces that backend
+func Configure(name string) error {
+	for _, n := range Names() {
+		if n == name {
+			selected = name
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown clipboard backend '%s', expected one of %s", name, strings.Join(Names(), ", "))
+}
+
+// Use replaces the configured backend with b, e.g. a *Fake in tests, until restore is called
+func Use(b Backend) (restore func()) {
+	previous := override
+	override = b
+	return func() { override = previous }
+}
+
+// Current returns the backend Copy uses, nil when none is available
+func Current() Backend {
+	if override != nil {
+		return override
+	}
+	return system.resolve(selected)
+}
+
+// Copy copies text with the current backend
+func Copy(text string) error {
+	b := Current()
+	if b == nil {
+		return ErrUnavailable
+	}
+	return b.Copy(text)
+}
+
+// resolve returns the backend of a configured name, probing the session for Auto
+func (e env) resolve(name string) Backend {
+	switch name {
+	case None:
+		return nil
+	case OSC52:
+		return &osc52{tmux: e.getenv("TMUX") != ""}
+	case Auto:
+		return e.probe()
+	}
+	for _, b := range backends {
+		if b.name == name {
+			return b
+		}
+	}
+	return nil
+}
+
+// probe returns the first backend the session supports and that is installed. OSC 52 is the
+// last resort, used over SSH where no local utility reaches the clipboard of the user.
+func (e env) probe() Backend {
+	for _, b := range backends {
+		if !b.usable(e) {
+			continue
+		}
+		if _, err := e.lookPath(b.args[0]); err == nil {
+			return b
+		}
+	}
+	if e.getenv("SSH_TTY") != "" || e.getenv("SSH_CONNECTION") != "" {
+		return &osc52{tmux: e.getenv("TMUX") != ""}
+	}
+	return nil
+}
+
+// isWSL reports whether the process runs in the Windows Subsystem for Linux
+func isWSL() bool {
+	if os.Getenv("WSL_DISTRO_NAME") != "" {
+		return true
+	}
+	version, err := os.ReadFile("/proc/sys/kernel/osrelease")
+	return err == nil && strings.Contains(strings.ToLower(string(version)), "microsoft")
+}
+
+// Fake is a backend that records the copied text instead of touching the clipboard
+type Fake struct {
+	Text   string // text of the last copy
+	Copies int    // number of copies
+	Err    error  // returned by Copy when set, simulating a failing utility
+}
+
+func (f *Fake) Name() string { return "fake" }
+
+func (f *Fake) Copy(text string) error {
+	if f.Err != nil {
+		return f.Err
+	}
+	f.Text = text
+	f.Copies++
+	return nil
+}
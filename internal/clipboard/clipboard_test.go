@@ -0,0 +1,107 @@
+package clipboard
+
+import (
+	"bytes"
+	"errors"
+	"os/exec"
+	"testing"
+)
+
+// fakeEnv returns an environment with the given variables and installed utilities
+func fakeEnv(goos string, wsl bool, vars map[string]string, installed ...string) env {
+	return env{
+		goos:   goos,
+		getenv: func(key string) string { return vars[key] },
+		lookPath: func(file string) (string, error) {
+			for _, name := range installed {
+				if name == file {
+					return "/usr/bin/" + file, nil
+				}
+			}
+			return "", exec.ErrNotFound
+		},
+		isWSL: func() bool { return wsl },
+	}
+}
+
+func TestProbe(t *testing.T) {
+	tests := []struct {
+		name      string
+		env       env
+		configure string
+		expected  string
+	}{
+		{"Wayland first", fakeEnv("linux", false, map[string]string{"WAYLAND_DISPLAY": "wayland-0", "DISPLAY": ":0"}, "wl-copy", "xclip"), Auto, "wl-copy"},
+		{"Wayland without wl-copy", fakeEnv("linux", false, map[string]string{"WAYLAND_DISPLAY": "wayland-0", "DISPLAY": ":0"}, "xclip"), Auto, "xclip"},
+		{"WSL", fakeEnv("linux", true, nil, "clip.exe", "xclip"), Auto, "clip.exe"},
+		{"tmux before X11", fakeEnv("linux", false, map[string]string{"TMUX": "/tmp/tmux-1/default", "DISPLAY": ":0"}, "tmux", "xclip"), Auto, "tmux"},
+		{"xsel without xclip", fakeEnv("linux", false, map[string]string{"DISPLAY": ":0"}, "xsel"), Auto, "xsel"},
+		{"X11 utility without display", fakeEnv("linux", false, nil, "xclip"), Auto, ""},
+		{"SSH falls back to OSC 52", fakeEnv("linux", false, map[string]string{"SSH_TTY": "/dev/pts/1"}, "xclip"), Auto, OSC52},
+		{"macOS", fakeEnv("darwin", false, nil, "pbcopy"), Auto, "pbcopy"},
+		{"Windows", fakeEnv("windows", false, nil, "clip"), Auto, "clip"},
+		{"Forced backend", fakeEnv("linux", false, map[string]string{"WAYLAND_DISPLAY": "wayland-0"}, "wl-copy"), "xsel", "xsel"},
+		{"Forced OSC 52", fakeEnv("linux", false, nil), OSC52, OSC52},
+		{"Disabled", fakeEnv("darwin", false, nil, "pbcopy"), None, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ""
+			if b := tt.env.resolve(tt.configure); b != nil {
+				got = b.Name()
+			}
+			if got != tt.expected {
+				t.Errorf("resolve(%q) = %q; want %q", tt.configure, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestConfigure(t *testing.T) {
+	defer Configure(Auto)
+	for _, name := range Names() {
+		if err := Configure(name); err != nil {
+			t.Errorf("Configure(%q) error = %v", name, err)
+		}
+	}
+	if err := Configure("xerox"); err == nil {
+		t.Error("Configure(xerox) should fail")
+	}
+
+	Configure(None)
+	if err := Copy("text"); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("Copy() with no backend error = %v; want ErrUnavailable", err)
+	}
+}
+
+func TestFake(t *testing.T) {
+	fake := &Fake{}
+	restore := Use(fake)
+	if err := Copy("feat: add x"); err != nil {
+		t.Fatalf("Copy() error = %v", err)
+	}
+	if fake.Text != "feat: add x" || fake.Copies != 1 || Current() != Backend(fake) {
+		t.Errorf("Fake recorded %q in %d copies", fake.Text, fake.Copies)
+	}
+	restore()
+	if Current() == Backend(fake) {
+		t.Error("restore did not remove the fake backend")
+	}
+}
+
+func TestOSC52(t *testing.T) {
+	tests := []struct {
+		tmux     bool
+		expected string
+	}{
+		{false, "\x1b]52;c;aGk=\a"},
+		{true, "\x1bPtmux;\x1b\x1b]52;c;aGk=\a\x1b\\"},
+	}
+	for _, tt := range tests {
+		var out bytes.Buffer
+		if err := (&osc52{tmux: tt.tmux}).write(&out, "hi"); err != nil || out.String() != tt.expected {
+			t.Errorf("osc52{tmux: %v} wrote %q, %v; want %q", tt.tmux, out.String(), err, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,334 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"git-commit/internal/clipboard"
+	"git-commit/internal/lint"
+	"git-commit/internal/provider"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the name of the configuration file in the .git-commit folder and the user config directory
+const FileName = "config.yaml"
+
+// Output sinks for generated prompts and messages
+const (
+	OutputClipboard      = "clipboard"       // copy to the clipboard
+	OutputStdout         = "stdout"          // print to standard output
+	OutputFile           = "file"            // write to the file named after a colon, e.g. "file:prompt.md"
+	OutputEditor         = "editor"          // open in the git editor and print the edited text
+	OutputCommitTemplate = "commit-template" // write the message to .git/COMMIT_EDITMSG for "git commit -e -F"
+	OutputJSON           = "json"            // print a JSON object with the text and what it describes
+)
+
+// Outputs returns the output sinks accepted by ParseOutput, "file:<path>" for OutputFile
+func Outputs() []string {
+	return []string{OutputClipboard, OutputStdout, OutputFile + ":<path>", OutputEditor, OutputCommitTemplate, OutputJSON}
+}
+
+// ParseOutput splits an output specification such as "stdout" or "file:out.md" into the sink and its file
+func ParseOutput(spec string) (sink, path string, err error) {
+	sink, path, hasPath := strings.Cut(spec, ":")
+	switch {
+	case sink == OutputFile && hasPath && path != "":
+		return sink, path, nil
+	case hasPath:
+	case sink == OutputClipboard, sink == OutputStdout, sink == OutputEditor, sink == OutputCommitTemplate, sink == OutputJSON:
+		return sink, "", nil
+	}
+	return "", "", fmt.Errorf("invalid output '%s', expected one of %s", spec, strings.Join(Outputs(), ", "))
+}
+
+// DefaultDiffMaxTokens is the token budget of the diff when no config.yaml sets one
+const DefaultDiffMaxTokens = 16000
+
+// Limits of directory contexts when no config.yaml sets them
+const (
+	DefaultContextMaxFileBytes = 64 * 1024  // larger files are listed without their content
+	DefaultContextMaxBytes     = 256 * 1024 // content of all files of one directory
+	DefaultContextMaxDepth     = 5          // deeper directories are listed without their files
+)
+
+// Config holds the settings read from config.yaml files
+type Config struct {
+	Prompt    string            // custom prompt used when no prompt name is given, "" for the default prompt
+	Output    string            // where prompts go, one of Outputs()
+	Clipboard string            // clipboard backend, clipboard.Auto probes the session for one
+	Provider  Provider          // model provider settings used by generate and the hooks
+	Diff      Diff              // limits applied to the staged diff
+	Context   Context           // limits applied to @context: directories
+	Lint      map[string]string // lint rule overrides, rule ID to "<off|warning|error> [value]"
+}
+
+// Provider holds the model provider settings
+type Provider struct {
+	Name      string
+	Model     string
+	BaseURL   string
+	Timeout   time.Duration
+	MaxTokens int
+}
+
+// Diff holds the limits applied to the staged diff
+type Diff struct {
+	ContextLines int // lines of context around each change, -1 for git's default
+	MaxBytes     int // maximum size of the diff in bytes, 0 for no limit
+	MaxTokens    int // estimated token budget of the diff, 0 for no budget
+}
+
+// Context holds the limits applied when @context: expands a directory
+type Context struct {
+	MaxFileBytes int // maximum size of one file, 0 for no limit
+	MaxBytes     int // maximum size of the contents of all files, 0 for no limit
+	MaxDepth     int // levels of subdirectories expanded, 0 for no limit
+}
+
+// Error is a configuration problem located in a file
+type Error struct {
+	File    string
+	Line    int
+	Column  int // 0 when only the line is known
+	Message string
+}
+
+func (e *Error) Error() string {
+	if e.Column > 0 {
+		return fmt.Sprintf("%s:%d:%d: %s", e.File, e.Line, e.Column, e.Message)
+	}
+	return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Message)
+}
+
+// Default returns the configuration used when no config.yaml exists
+func Default() *Config {
+	return &Config{
+		Output:    OutputClipboard,
+		Clipboard: clipboard.Auto,
+		Diff:      Diff{ContextLines: -1, MaxTokens: DefaultDiffMaxTokens},
+		Context: Context{
+			MaxFileBytes: DefaultContextMaxFileBytes,
+			MaxBytes:     DefaultContextMaxBytes,
+			MaxDepth:     DefaultContextMaxDepth,
+		},
+		Lint: map[string]string{},
+	}
+}
+
+// UserDir returns the user configuration directory of git-commit, $XDG_CONFIG_HOME/git-commit
+// or ~/.config/git-commit, or "" if the home directory is unknown
+func UserDir() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "git-commit")
+}
+
+// Load merges the user-global config.yaml with the one in the .git-commit folder under root.
+// Repository settings override global ones; an empty root only loads the global file.
+func Load(root string) (*Config, error) {
+	cfg := Default()
+
+	var files []string
+	if dir := UserDir(); dir != "" {
+		files = append(files, filepath.Join(dir, FileName))
+	}
+	if root != "" {
+		files = append(files, filepath.Join(root, ".git-commit", FileName))
+	}
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config file %s: %v", file, err)
+		}
+		if err := cfg.Parse(file, data); err != nil {
+			return nil, err
+		}
+	}
+
+	return cfg, nil
+}
+
+// yamlLine extracts the line number from yaml syntax errors
+var yamlLine = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+
+// Parse applies the settings of a config.yaml file on top of cfg.
+// Errors are *Error values pointing at the offending line and column.
+func (cfg *Config) Parse(file string, data []byte) error {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		msg := strings.TrimPrefix(err.Error(), "yaml: ")
+		line := 1
+		if m := yamlLine.FindStringSubmatch(err.Error()); m != nil {
+			line, _ = strconv.Atoi(m[1])
+			msg = m[2]
+		}
+		return &Error{File: file, Line: line, Message: msg}
+	}
+	if len(doc.Content) == 0 {
+		// Empty file
+		return nil
+	}
+
+	p := &parser{file: file}
+	p.mapping(doc.Content[0], func(key, value *yaml.Node) {
+		switch key.Value {
+		case "prompt":
+			cfg.Prompt = p.str(value)
+		case "output":
+			cfg.Output = p.str(value)
+			if _, _, err := ParseOutput(cfg.Output); err != nil && p.err == nil {
+				p.fail(value, "%v", err)
+			}
+		case "clipboard":
+			cfg.Clipboard = p.enum(value, clipboard.Names()...)
+		case "provider":
+			p.mapping(value, func(key, value *yaml.Node) {
+				switch key.Value {
+				case "name":
+					cfg.Provider.Name = p.enum(value, provider.Names()...)
+				case "model":
+					cfg.Provider.Model = p.str(value)
+				case "base_url":
+					cfg.Provider.BaseURL = p.str(value)
+				case "timeout":
+					cfg.Provider.Timeout = p.duration(value)
+				case "max_tokens":
+					cfg.Provider.MaxTokens = p.integer(value, 1)
+				default:
+					p.fail(key, "unknown setting 'provider.%s'", key.Value)
+				}
+			})
+		case "diff":
+			p.mapping(value, func(key, value *yaml.Node) {
+				switch key.Value {
+				case "context_lines":
+					cfg.Diff.ContextLines = p.integer(value, 0)
+				case "max_bytes":
+					cfg.Diff.MaxBytes = p.integer(value, 0)
+				case "max_tokens":
+					cfg.Diff.MaxTokens = p.integer(value, 0)
+				default:
+					p.fail(key, "unknown setting 'diff.%s'", key.Value)
+				}
+			})
+		case "context":
+			p.mapping(value, func(key, value *yaml.Node) {
+				switch key.Value {
+				case "max_file_bytes":
+					cfg.Context.MaxFileBytes = p.integer(value, 0)
+				case "max_bytes":
+					cfg.Context.MaxBytes = p.integer(value, 0)
+				case "max_depth":
+					cfg.Context.MaxDepth = p.integer(value, 0)
+				default:
+					p.fail(key, "unknown setting 'context.%s'", key.Value)
+				}
+			})
+		case "lint":
+			rules := lint.DefaultConfig()
+			p.mapping(value, func(key, value *yaml.Node) {
+				setting := p.str(value)
+				if err := rules.Set(key.Value, setting); err != nil {
+					// Unknown rules are reported at the key, bad settings at the value
+					if _, known := rules[key.Value]; !known {
+						value = key
+					}
+					p.fail(value, "%v", err)
+				}
+				cfg.Lint[key.Value] = setting
+			})
+		default:
+			p.fail(key, "unknown setting '%s'", key.Value)
+		}
+	})
+
+	return p.err
+}
+
+// parser walks a YAML document and records the first validation error
+type parser struct {
+	file string
+	err  error
+}
+
+// fail records an error at node unless one was already recorded
+func (p *parser) fail(node *yaml.Node, format string, a ...interface{}) {
+	if p.err == nil {
+		p.err = &Error{File: p.file, Line: node.Line, Column: node.Column, Message: fmt.Sprintf(format, a...)}
+	}
+}
+
+// mapping calls fn for each key of a mapping node in document order until an error is recorded
+func (p *parser) mapping(node *yaml.Node, fn func(key, value *yaml.Node)) {
+	if node.Kind != yaml.MappingNode {
+		// A key without value, e.g. "diff:", decodes to null and is treated as empty
+		if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+			return
+		}
+		p.fail(node, "expected a mapping")
+		return
+	}
+	for i := 0; i+1 < len(node.Content) && p.err == nil; i += 2 {
+		fn(node.Content[i], node.Content[i+1])
+	}
+}
+
+// str returns the value of a scalar node
+func (p *parser) str(node *yaml.Node) string {
+	if node.Kind != yaml.ScalarNode {
+		p.fail(node, "expected a string")
+		return ""
+	}
+	return node.Value
+}
+
+// enum returns the value of a scalar node that must be one of allowed
+func (p *parser) enum(node *yaml.Node, allowed ...string) string {
+	value := p.str(node)
+	for _, a := range allowed {
+		if value == a {
+			return value
+		}
+	}
+	p.fail(node, "invalid value '%s', expected one of %s", value, strings.Join(allowed, ", "))
+	return ""
+}
+
+// integer returns the value of an integer node that must be at least min
+func (p *parser) integer(node *yaml.Node, min int) int {
+	var n int
+	if node.Kind != yaml.ScalarNode || node.Decode(&n) != nil {
+		p.fail(node, "expected an integer")
+		return 0
+	}
+	if n < min {
+		p.fail(node, "value %d must be at least %d", n, min)
+	}
+	return n
+}
+
+// duration returns the value of a duration node such as "30s" or "2m"
+func (p *parser) duration(node *yaml.Node) time.Duration {
+	d, err := time.ParseDuration(p.str(node))
+	if err != nil || d <= 0 {
+		p.fail(node, "expected a positive duration such as 30s or 2m")
+		return 0
+	}
+	return d
+}
@@ -0,0 +1,150 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	data := `# git-commit settings
+prompt: api
+output: stdout
+clipboard: xclip
+provider:
+  name: ollama
+  model: llama3.1
+  base_url: http://localhost:11434
+  timeout: 2m
+  max_tokens: 512
+diff:
+  context_lines: 1
+  max_bytes: 20000
+  max_tokens: 4000
+context:
+  max_file_bytes: 1000
+  max_bytes: 0
+  max_depth: 2
+lint:
+  header-max-length: error 72
+  type-enum: "off"
+`
+	cfg := Default()
+	if err := cfg.Parse("config.yaml", []byte(data)); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if cfg.Prompt != "api" || cfg.Output != OutputStdout || cfg.Clipboard != "xclip" {
+		t.Errorf("Unexpected top-level settings: %+v", cfg)
+	}
+	expectedProvider := Provider{Name: "ollama", Model: "llama3.1", BaseURL: "http://localhost:11434", Timeout: 2 * time.Minute, MaxTokens: 512}
+	if cfg.Provider != expectedProvider {
+		t.Errorf("Provider = %+v; want %+v", cfg.Provider, expectedProvider)
+	}
+	if cfg.Diff != (Diff{ContextLines: 1, MaxBytes: 20000, MaxTokens: 4000}) {
+		t.Errorf("Diff = %+v", cfg.Diff)
+	}
+	if cfg.Context != (Context{MaxFileBytes: 1000, MaxBytes: 0, MaxDepth: 2}) {
+		t.Errorf("Context = %+v", cfg.Context)
+	}
+	if cfg.Lint["header-max-length"] != "error 72" || cfg.Lint["type-enum"] != "off" {
+		t.Errorf("Lint = %v", cfg.Lint)
+	}
+}
+
+func TestParseOutput(t *testing.T) {
+	tests := []struct {
+		spec, sink, path string
+		valid            bool
+	}{
+		{"clipboard", OutputClipboard, "", true},
+		{"json", OutputJSON, "", true},
+		{"commit-template", OutputCommitTemplate, "", true},
+		{"file:out/prompt.md", OutputFile, "out/prompt.md", true},
+		{"file:", "", "", false},
+		{"stdout:x", "", "", false},
+		{"printer", "", "", false},
+	}
+
+	for _, tt := range tests {
+		sink, path, err := ParseOutput(tt.spec)
+		if (err == nil) != tt.valid || sink != tt.sink || path != tt.path {
+			t.Errorf("ParseOutput(%q) = %q, %q, %v", tt.spec, sink, path, err)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		line   int
+		column int
+	}{
+		{"Unknown key", "prompt: api\npromt: ui\n", 2, 1},
+		{"Unknown nested key", "provider:\n  name: openai\n  modle: gpt\n", 3, 3},
+		{"Invalid enum", "output: printer\n", 1, 9},
+		{"Output file without path", "output: file\n", 1, 9},
+		{"Unknown provider", "provider:\n  name: acme\n", 2, 9},
+		{"Not an integer", "diff:\n  max_bytes: lots\n", 2, 14},
+		{"Negative integer", "diff:\n  context_lines: -1\n", 2, 18},
+		{"Negative token budget", "diff:\n  max_tokens: -5\n", 2, 15},
+		{"Unknown context key", "context:\n  depth: 2\n", 2, 3},
+		{"Negative depth", "context:\n  max_depth: -1\n", 2, 14},
+		{"Bad duration", "provider:\n  timeout: soon\n", 2, 12},
+		{"Section is not a mapping", "diff: 10\n", 1, 7},
+		{"Unknown lint rule", "lint:\n  no-such-rule: error\n", 2, 3},
+		{"Bad lint severity", "lint:\n  type-enum: fatal\n", 2, 14},
+		{"Syntax error", "prompt: api\n  output: [stdout\n", 2, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Default().Parse("config.yaml", []byte(tt.data))
+			var cfgErr *Error
+			if !errors.As(err, &cfgErr) {
+				t.Fatalf("Parse() error = %v; want *Error", err)
+			}
+			if cfgErr.Line != tt.line || (tt.column > 0 && cfgErr.Column != tt.column) {
+				t.Errorf("Parse() error at %d:%d; want %d:%d (%v)", cfgErr.Line, cfgErr.Column, tt.line, tt.column, err)
+			}
+		})
+	}
+}
+
+func TestLoadMergesGlobalAndRepository(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", home)
+	root := t.TempDir()
+
+	global := "output: stdout\nprovider:\n  name: openai\n  model: gpt-4o\nlint:\n  type-enum: warning\n"
+	repo := "provider:\n  model: gpt-4o-mini\nlint:\n  header-max-length: error 72\n"
+	if err := os.MkdirAll(filepath.Join(home, "git-commit"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(home, "git-commit", FileName), []byte(global), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(root, ".git-commit"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, ".git-commit", FileName), []byte(repo), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(root)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Output != OutputStdout || cfg.Provider.Name != "openai" || cfg.Provider.Model != "gpt-4o-mini" {
+		t.Errorf("Unexpected merged config: %+v", cfg)
+	}
+	if cfg.Lint["type-enum"] != "warning" || cfg.Lint["header-max-length"] != "error 72" {
+		t.Errorf("Lint overrides not merged: %v", cfg.Lint)
+	}
+	if cfg.Clipboard != "auto" || cfg.Diff.ContextLines != -1 || cfg.Diff.MaxTokens != DefaultDiffMaxTokens {
+		t.Errorf("Defaults not kept for unset settings: %+v", cfg)
+	}
+}
@@ -0,0 +1,157 @@
+package diff
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// EstimateTokens approximates the number of model tokens in text.
+// Code and diffs average about four bytes per token across the common tokenizers.
+func EstimateTokens(text string) int {
+	return (len(text) + 3) / 4
+}
+
+// Priorities of files when the budget is tight, higher is kept first
+const (
+	priorityLockfile = iota
+	priorityGenerated
+	priorityData
+	prioritySource
+)
+
+// lockfiles are dependency lock files whose diffs rarely help describe a change
+var lockfiles = map[string]bool{
+	"package-lock.json": true, "yarn.lock": true, "pnpm-lock.yaml": true, "npm-shrinkwrap.json": true,
+	"go.sum": true, "Cargo.lock": true, "Gemfile.lock": true, "composer.lock": true,
+	"poetry.lock": true, "Pipfile.lock": true, "uv.lock": true, "mix.lock": true, "pubspec.lock": true,
+}
+
+// dataExtensions are documentation and data files, kept after source files
+var dataExtensions = map[string]bool{
+	".md": true, ".txt": true, ".rst": true, ".json": true, ".yaml": true, ".yml": true,
+	".toml": true, ".xml": true, ".csv": true, ".svg": true, ".html": true, ".lock": true,
+}
+
+// filePriority ranks a file so that source files survive a tight budget.
+// Deleted files rank with data files, their removed lines rarely explain a change.
+func filePriority(f *File) int {
+	file := f.Path()
+	base := path.Base(file)
+	switch {
+	case lockfiles[base]:
+		return priorityLockfile
+	case strings.Contains(base, ".min."), strings.HasSuffix(base, ".map"), strings.HasSuffix(base, ".pb.go"),
+		strings.HasPrefix(file, "vendor/"), strings.Contains(file, "/vendor/"),
+		strings.HasPrefix(file, "node_modules/"), strings.Contains(file, "/dist/"), strings.HasPrefix(file, "dist/"):
+		return priorityGenerated
+	case f.Status == Deleted, dataExtensions[path.Ext(base)]:
+		return priorityData
+	}
+	return prioritySource
+}
+
+// Elision describes a file that was shortened to fit the budget
+type Elision struct {
+	Path       string
+	Stat       string // "+added -removed" of the whole file
+	HunksShown int
+	HunksTotal int
+}
+
+// Collapsed reports whether the file was reduced to its stat line
+func (e Elision) Collapsed() bool {
+	return e.HunksShown == 0
+}
+
+// BudgetReport describes what Fit left out
+type BudgetReport struct {
+	MaxTokens       int
+	EstimatedTokens int // estimate for the full diff
+	Elided          []Elision
+}
+
+// Truncated reports whether anything was left out
+func (r BudgetReport) Truncated() bool {
+	return len(r.Elided) > 0
+}
+
+// Fit renders d within roughly maxTokens estimated tokens. Files are filled in priority
+// order, source files before data files, generated files and lock files. Hunks are kept whole
+// in their original order; files that do not fit at all are collapsed into a stat line such as
+// "+120 -40". A summary of the elided content is appended so the model knows the diff is partial.
+// A maxTokens of zero or less disables the budget.
+func Fit(d *Diff, maxTokens int) (string, BudgetReport) {
+	text := d.String()
+	report := BudgetReport{MaxTokens: maxTokens, EstimatedTokens: EstimateTokens(text)}
+	if maxTokens <= 0 || report.EstimatedTokens <= maxTokens {
+		return text, report
+	}
+
+	// Every file keeps at least its header, a marker and a summary line, reserve that first
+	used := EstimateTokens(summaryHeader(report)) + EstimateTokens(strings.Join(d.Preamble, "\n"))
+	for _, f := range d.Files {
+		worst := Elision{Path: f.Path(), Stat: f.Stat(), HunksShown: len(f.Hunks), HunksTotal: len(f.Hunks)}
+		used += EstimateTokens(strings.Join(f.Header, "\n")) + EstimateTokens(omittedMarker(worst)) + EstimateTokens(summaryLine(worst))
+	}
+
+	order := make([]*File, len(d.Files))
+	copy(order, d.Files)
+	sort.SliceStable(order, func(i, j int) bool { return filePriority(order[i]) > filePriority(order[j]) })
+
+	shown := map[*File]int{}
+	for _, f := range order {
+		for _, h := range f.Hunks {
+			cost := EstimateTokens(h.String())
+			if used+cost > maxTokens {
+				break
+			}
+			used += cost
+			shown[f]++
+		}
+	}
+
+	var b strings.Builder
+	for _, line := range d.Preamble {
+		b.WriteString(line + "\n")
+	}
+	for _, f := range d.Files {
+		n := shown[f]
+		b.WriteString((&File{Header: f.Header, Hunks: f.Hunks[:n]}).String())
+		if n < len(f.Hunks) {
+			e := Elision{Path: f.Path(), Stat: f.Stat(), HunksShown: n, HunksTotal: len(f.Hunks)}
+			report.Elided = append(report.Elided, e)
+			b.WriteString(omittedMarker(e))
+		}
+	}
+
+	b.WriteString(summaryHeader(report))
+	for _, e := range report.Elided {
+		b.WriteString(summaryLine(e))
+	}
+
+	return b.String(), report
+}
+
+// omittedMarker replaces the hunks of a file that were left out
+func omittedMarker(e Elision) string {
+	if e.Collapsed() {
+		return fmt.Sprintf("@@ diff omitted to fit the token budget: %s @@\n", e.Stat)
+	}
+	return fmt.Sprintf("@@ %d more hunks omitted to fit the token budget @@\n", e.HunksTotal-e.HunksShown)
+}
+
+// summaryHeader introduces the list of elided files appended by Fit
+func summaryHeader(r BudgetReport) string {
+	return fmt.Sprintf("\n[git-commit: the diff above was shortened from about %d to %d tokens; these changes are not shown in full]\n",
+		r.EstimatedTokens, r.MaxTokens)
+}
+
+// summaryLine describes an elided file in the summary appended by Fit
+func summaryLine(e Elision) string {
+	if e.Collapsed() {
+		return fmt.Sprintf("  %s | %s (collapsed)\n", e.Path, e.Stat)
+	}
+	return fmt.Sprintf("  %s | %s (%d of %d hunks shown)\n", e.Path, e.Stat, e.HunksShown, e.HunksTotal)
+}
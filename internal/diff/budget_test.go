@@ -0,0 +1,118 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// fileSection builds the diff of one file with the given number of hunks, each adding lines lines
+func fileSection(path string, hunks, lines int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "diff --git a/%s b/%s\nindex 1111111..2222222 100644\n--- a/%s\n+++ b/%s\n", path, path, path, path)
+	for h := 0; h < hunks; h++ {
+		fmt.Fprintf(&b, "@@ -%d,1 +%d,%d @@\n context\n", h*100+1, h*100+1, lines+1)
+		for i := 0; i < lines; i++ {
+			fmt.Fprintf(&b, "+%s line %d of hunk %d\n", path, i, h)
+		}
+	}
+	return b.String()
+}
+
+func TestFilePriority(t *testing.T) {
+	tests := []struct {
+		path     string
+		status   Status
+		expected int
+	}{
+		{"cmd/main.go", Modified, prioritySource},
+		{"web/package-lock.json", Modified, priorityLockfile},
+		{"go.sum", Added, priorityLockfile},
+		{"static/app.min.js", Modified, priorityGenerated},
+		{"vendor/lib/x.go", Modified, priorityGenerated},
+		{"api/service.pb.go", Modified, priorityGenerated},
+		{"README.md", Modified, priorityData},
+		{"config/settings.yaml", Modified, priorityData},
+		{"cmd/old.go", Deleted, priorityData},
+	}
+
+	for _, tt := range tests {
+		f := &File{Status: tt.status, OldPath: tt.path}
+		if tt.status != Deleted {
+			f.NewPath = tt.path
+		}
+		if got := filePriority(f); got != tt.expected {
+			t.Errorf("filePriority(%q, %s) = %d; want %d", tt.path, tt.status, got, tt.expected)
+		}
+	}
+}
+
+func TestFitUnderBudget(t *testing.T) {
+	input := fileSection("main.go", 2, 3)
+	got, report := Fit(Parse(input), 10000)
+	if got != input || report.Truncated() {
+		t.Errorf("Fit() changed a diff within the budget")
+	}
+	got, report = Fit(Parse(input), 0)
+	if got != input || report.Truncated() {
+		t.Errorf("Fit() with a zero budget should not change the diff")
+	}
+}
+
+func TestFitCollapsesLockfilesFirst(t *testing.T) {
+	source := fileSection("main.go", 1, 20)
+	lock := fileSection("package-lock.json", 1, 200)
+	input := lock + source
+	budget := EstimateTokens(source) + 300
+
+	got, report := Fit(Parse(input), budget)
+	if len(report.Elided) != 1 || report.Elided[0].Path != "package-lock.json" || !report.Elided[0].Collapsed() {
+		t.Fatalf("Fit() elided %+v; want package-lock.json collapsed", report.Elided)
+	}
+	if !strings.Contains(got, source) {
+		t.Error("Fit() dropped the source file")
+	}
+	if !strings.Contains(got, "diff --git a/package-lock.json b/package-lock.json\n") {
+		t.Error("Fit() dropped the header of the collapsed file")
+	}
+	if !strings.Contains(got, "@@ diff omitted to fit the token budget: +200 -0 @@\n") {
+		t.Errorf("Fit() output lacks the collapsed marker:\n%s", got)
+	}
+	if !strings.Contains(got, "  package-lock.json | +200 -0 (collapsed)\n") {
+		t.Errorf("Fit() output lacks the summary line:\n%s", got)
+	}
+	if strings.Index(got, "package-lock.json") > strings.Index(got, "main.go") {
+		t.Error("Fit() did not keep the original file order")
+	}
+	if EstimateTokens(got) > budget {
+		t.Errorf("Fit() output has about %d tokens; want at most %d", EstimateTokens(got), budget)
+	}
+}
+
+func TestFitKeepsWholeHunks(t *testing.T) {
+	input := fileSection("main.go", 4, 30)
+	hunks := Parse(input).Files[0].Hunks
+	budget := EstimateTokens(input) - EstimateTokens(hunks[3].String())
+
+	got, report := Fit(Parse(input), budget)
+	if len(report.Elided) != 1 {
+		t.Fatalf("Fit() elided %+v; want one partial file", report.Elided)
+	}
+	e := report.Elided[0]
+	if e.Collapsed() || e.HunksTotal != 4 || e.HunksShown == 0 || e.HunksShown == 4 {
+		t.Fatalf("Unexpected elision %+v", e)
+	}
+	for i, h := range hunks {
+		if kept := strings.Contains(got, h.String()); kept != (i < e.HunksShown) {
+			t.Errorf("Hunk %d kept = %v with %d hunks shown", i, kept, e.HunksShown)
+		}
+	}
+	marker := fmt.Sprintf("@@ %d more hunks omitted to fit the token budget @@\n", 4-e.HunksShown)
+	if !strings.Contains(got, marker) {
+		t.Errorf("Fit() output lacks %q", marker)
+	}
+	summary := fmt.Sprintf("  main.go | +120 -0 (%d of 4 hunks shown)\n", e.HunksShown)
+	if !strings.HasSuffix(got, summary) {
+		t.Errorf("Fit() output does not end with %q:\n%s", summary, got)
+	}
+}
@@ -1,112 +1,205 @@
 package diff
 
 import (
-	"bytes"
+	"errors"
 	"fmt"
+	"git-commit/internal/config"
 	"git-commit/internal/git"
-	"log"
 	"os"
-	"os/exec"
 	"strings"
 )
 
+// ErrNoChanges is returned when a source has nothing to describe once ignored files are left out
+var ErrNoChanges = errors.New("no changes detected")
+
+// ErrNoStagedChanges is ErrNoChanges for the staged changes, the default source
+var ErrNoStagedChanges = fmt.Errorf("%w, use 'git add' to stage files", ErrNoChanges)
+
 //
-//	getFilesToIgnore reads the .gitdiffignore file, get staged files, and determines which files need to be ignored
+//	getFilesToIgnore gets the files changed by src and determines which ones the layered ignore files exclude
 //
-func getFilesToIgnore() []string {
-	// 1. Read the .gitdiffignore file and get ignore patterns
-	patterns, err := git.ParseGitDiffIgnore()
+func getFilesToIgnore(repo *git.Repo, src Source) ([]string, error) {
+	// 1. Get the list of changed files
+	changedFiles, err := git.GetDiffFiles(src.gitArgs())
 	if err != nil {
-		log.Printf("Error reading .gitdiffignore: %v", err)
+		return nil, err
 	}
 
-	// 2. Get the list of staged files
-	stagedFiles, err := git.GetStagedFiles()
+	// 2. Determine files that need to be ignored from the global, repository and per-directory ignore files
+	filesToIgnore, err := git.GetIgnoredFiles(repo, changedFiles)
 	if err != nil {
-		log.Fatalf("Error getting staged files: %v", err)
+		return nil, fmt.Errorf("error reading ignore files: %w", err)
 	}
-
-	// 3. Determine files that need to be ignored
-	filesToIgnore := git.GetFilesToIgnore(patterns, stagedFiles)
-	return filesToIgnore
+	return filesToIgnore, nil
 }
 
-func removeFilesFromStaged(filesToIgnore []string) {
-		// 4. Remove ignored files from staged
+func reportIgnoredFiles(filesToIgnore []string) {
+	// 3. Tell the user which changed files are left out of the diff
 	if len(filesToIgnore) > 0 {
-		fmt.Printf("Ignoring %d files based on .gitdiffignore:\n", len(filesToIgnore))
+		fmt.Fprintf(os.Stderr, "Ignoring %d files based on ignore rules:\n", len(filesToIgnore))
 		for _, file := range filesToIgnore {
-			fmt.Printf("  - %s\n", file)
+			fmt.Fprintf(os.Stderr, "  - %s\n", file)
 		}
+	}
+}
 
-		err := git.RemoveFilesFromStaged(filesToIgnore)
-		if err != nil {
-			log.Printf("Error removing files from staged: %v", err)
-		}
+func parseGitDiff(src Source, filesToIgnore []string, contextLines int) (string, error) {
+	// 4. Get git diff, excluding ignored files through pathspecs so the index is never touched
+	output, err := git.GetDiff(src.gitArgs(), filesToIgnore, contextLines)
+	if err != nil {
+		return "", err
+	}
+
+	diffOutput := strings.TrimSpace(output)
+	if diffOutput == "" {
+		return "", noChanges(src)
 	}
+	return diffOutput, nil
 }
 
+// noChanges returns the error telling that src has nothing to describe
+func noChanges(src Source) error {
+	if src.Kind == "" || src.Kind == SourceStaged {
+		return ErrNoStagedChanges
+	}
+	return fmt.Errorf("%w in %s", ErrNoChanges, src)
+}
 
-func addedFilesToStaged(filesToIgnore []string) []string {
-	// 8. Return ignored files back to staged
-	if len(filesToIgnore) > 0 {
-		fmt.Printf("Returning %d files to staged:\n", len(filesToIgnore))
-		for _, file := range filesToIgnore {
-			fmt.Printf("  + %s\n", file)
-		}
+// loadPatch parses the patch of src and drops the files matched by the ignore files
+func loadPatch(repo *git.Repo, src Source) (*Diff, error) {
+	text, err := src.readPatch()
+	if err != nil {
+		return nil, err
+	}
 
-		err := git.AddFilesToStaged(filesToIgnore)
-		if err != nil {
-			log.Printf("Error returning files to staged: %v", err)
-		} else {
-			fmt.Println("Files successfully returned to staged.")
-		}
+	d := Parse(text)
+	ignoredFiles, err := git.GetIgnoredFiles(repo, d.Paths())
+	if err != nil {
+		return nil, fmt.Errorf("error reading ignore files: %w", err)
+	}
+	reportIgnoredFiles(ignoredFiles)
+
+	ignored := map[string]bool{}
+	for _, file := range ignoredFiles {
+		ignored[file] = true
+	}
+	d = d.Filter(func(f *File) bool { return !ignored[f.Path()] })
+
+	if len(d.Files) == 0 {
+		return nil, noChanges(src)
+	}
+	return d, nil
+}
+
+// truncate cuts the diff at the last complete line that fits in maxBytes and notes what was left out
+func truncate(diffOutput string, maxBytes int) string {
+	if maxBytes <= 0 || len(diffOutput) <= maxBytes {
+		return diffOutput
 	}
 
-	return filesToIgnore
+	cut := strings.LastIndex(diffOutput[:maxBytes], "\n")
+	if cut < 0 {
+		cut = maxBytes
+	}
+	fmt.Fprintf(os.Stderr, "Diff truncated to %d of %d bytes (diff.max_bytes)\n", cut, len(diffOutput))
+	return diffOutput[:cut] + fmt.Sprintf("\n... diff truncated, %d more bytes not shown", len(diffOutput)-cut)
 }
 
-func parseGitDiff(filesToIgnore []string) string {
-	// 5. Get git diff
-	cmd := exec.Command("git", "diff", "--staged")
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-	err := cmd.Run()
+// reportBudget notes on stderr which files were shortened to fit the token budget
+func reportBudget(report BudgetReport) {
+	if !report.Truncated() {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Diff shortened from about %d to %d tokens (diff.max_tokens):\n", report.EstimatedTokens, report.MaxTokens)
+	for _, e := range report.Elided {
+		fmt.Fprintf(os.Stderr, "  - %s", summaryLine(e)[2:])
+	}
+}
+
+// Load returns the parsed changes of src without the files matched by the ignore files.
+// The index is left untouched, so partially staged files and concurrent git commands are safe.
+// It fails with ErrNoStagedChanges or ErrNoChanges when nothing is left to describe,
+// and with a *git.CommandError when git fails.
+func Load(repo *git.Repo, src Source) (*Diff, error) {
+	return load(repo, src, repo.Settings().Diff.ContextLines)
+}
 
+// load is Load with the lines of context around each change, a negative value keeps git's default
+func load(repo *git.Repo, src Source, contextLines int) (*Diff, error) {
+	if src.Kind == SourcePatch {
+		return loadPatch(repo, src)
+	}
+	ignoredFiles, err := getFilesToIgnore(repo, src)
+	if err != nil {
+		return nil, err
+	}
+	reportIgnoredFiles(ignoredFiles)
+	text, err := parseGitDiff(src, ignoredFiles, contextLines)
 	if err != nil {
-		// If an error occurred, return the ignored files back to staged
-		if len(filesToIgnore) > 0 {
-			fmt.Println("An error occurred, returning files back to staged...")
-			addErr := git.AddFilesToStaged(filesToIgnore)
-			if addErr != nil {
-				log.Printf("Error returning files to staged: %v", addErr)
-			}
+		return nil, err
+	}
+	return Parse(text), nil
+}
+
+// ChangedFiles returns the root-relative paths changed by src without the files matched by the ignore files
+func ChangedFiles(repo *git.Repo, src Source) ([]string, error) {
+	var files []string
+	if src.Kind == SourcePatch {
+		text, err := src.readPatch()
+		if err != nil {
+			return nil, err
+		}
+		files = Parse(text).Paths()
+	} else {
+		var err error
+		if files, err = git.GetDiffFiles(src.gitArgs()); err != nil {
+			return nil, err
 		}
-		log.Fatalf("Error executing git diff: %v\n%s", err, stderr.String())
 	}
 
-	diffOutput := strings.TrimSpace(stdout.String())
-	if diffOutput == "" {
-		// If there are no changes, return the ignored files back to staged
-		if len(filesToIgnore) > 0 {
-			fmt.Println("No changes found, returning files back to staged...")
-			addErr := git.AddFilesToStaged(filesToIgnore)
-			if addErr != nil {
-				log.Printf("Error returning files to staged: %v", addErr)
-			}
+	ignoredFiles, err := git.GetIgnoredFiles(repo, files)
+	if err != nil {
+		return nil, err
+	}
+	ignored := map[string]bool{}
+	for _, file := range ignoredFiles {
+		ignored[file] = true
+	}
+	kept := []string{}
+	for _, file := range files {
+		if !ignored[file] {
+			kept = append(kept, file)
 		}
-		fmt.Println("No changes detected. Please use 'git add' to add files to staged.")
-		os.Exit(1)
 	}
-	return diffOutput
+	return kept, nil
 }
 
-func GetDiffOutputWithoutIgnoresFiles() string {
-	ignoredFiles := getFilesToIgnore()
-	removeFilesFromStaged(ignoredFiles)
-	diffOutput := parseGitDiff(ignoredFiles)
-	addedFilesToStaged(ignoredFiles)
+// Staged returns the parsed staged diff without the files matched by the ignore files
+func Staged(repo *git.Repo) (*Diff, error) {
+	return Load(repo, Source{Kind: SourceStaged})
+}
 
-	return diffOutput
-}
\ No newline at end of file
+// Render formats d for a prompt: a summary of the changed files followed by the diff,
+// shortened to the token budget and size limit of limits
+func Render(d *Diff, limits config.Diff) string {
+	diffOutput, report := Fit(d, limits.MaxTokens)
+	reportBudget(report)
+	diffOutput = truncate(strings.TrimSuffix(diffOutput, "\n"), limits.MaxBytes)
+	return "Changed files:\n" + d.Summary() + "\n" + diffOutput
+}
+
+// GetDiffOutput returns the changes of src without the files matched by the ignore files,
+// rendered for a prompt within the diff settings of the repository configuration
+func GetDiffOutput(repo *git.Repo, src Source) (string, error) {
+	d, err := Load(repo, src)
+	if err != nil {
+		return "", err
+	}
+	return Render(d, repo.Settings().Diff), nil
+}
+
+// GetDiffOutputWithoutIgnoresFiles returns the staged diff without the files matched by the ignore files,
+// rendered for a prompt within the diff settings of the repository configuration
+func GetDiffOutputWithoutIgnoresFiles(repo *git.Repo) (string, error) {
+	return GetDiffOutput(repo, Source{Kind: SourceStaged})
+}
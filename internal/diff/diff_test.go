@@ -0,0 +1,106 @@
+package diff
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"git-commit/internal/git"
+)
+
+// initRepo creates an empty repository in a temporary directory and changes into it
+func initRepo(t *testing.T) *git.Repo {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	t.Setenv("GIT_CEILING_DIRECTORIES", dir)
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	wd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+	if out, err := exec.Command("git", "init", "-q").CombinedOutput(); err != nil {
+		t.Fatalf("git init failed: %v\n%s", err, out)
+	}
+	return &git.Repo{Root: dir}
+}
+
+func TestLoadNoChanges(t *testing.T) {
+	repo := initRepo(t)
+
+	if _, err := Load(repo, Source{Kind: SourceStaged}); !errors.Is(err, ErrNoStagedChanges) {
+		t.Errorf("Load(staged) error = %v; want ErrNoStagedChanges", err)
+	}
+	_, err := Load(repo, PatchSource(strings.NewReader("")))
+	if !errors.Is(err, ErrNoChanges) || errors.Is(err, ErrNoStagedChanges) {
+		t.Errorf("Load(patch) error = %v; want ErrNoChanges", err)
+	}
+	if _, err := Load(repo, Source{Kind: SourceCommit, Rev: "HEAD"}); !errors.Is(err, git.ErrGitFailed) {
+		t.Errorf("Load(commit without commits) error = %v; want ErrGitFailed", err)
+	}
+}
+
+func TestLoadIgnoresNonASCIIPaths(t *testing.T) {
+	repo := initRepo(t)
+	for name, content := range map[string]string{".git-commit/ignore": "*.lock\n", "ünï.lock": "lock", "main.go": "package main\n"} {
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if out, err := exec.Command("git", "add", "ünï.lock", "main.go").CombinedOutput(); err != nil {
+		t.Fatalf("git add failed: %v\n%s", err, out)
+	}
+
+	d, err := Load(repo, Source{Kind: SourceStaged})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if paths := d.Paths(); len(paths) != 1 || paths[0] != "main.go" {
+		t.Errorf("Load() paths = %q; want [main.go]", paths)
+	}
+}
+
+func TestLoadAmendRootCommit(t *testing.T) {
+	repo := initRepo(t)
+	for _, key := range []string{"GIT_AUTHOR_NAME", "GIT_COMMITTER_NAME"} {
+		t.Setenv(key, "Test")
+	}
+	for _, key := range []string{"GIT_AUTHOR_EMAIL", "GIT_COMMITTER_EMAIL"} {
+		t.Setenv(key, "test@example.com")
+	}
+	for _, name := range []string{"a.go", "b.go"} {
+		if err := os.WriteFile(name, []byte("package main\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if out, err := exec.Command("git", "add", name).CombinedOutput(); err != nil {
+			t.Fatalf("git add failed: %v\n%s", err, out)
+		}
+		if name == "a.go" {
+			if out, err := exec.Command("git", "commit", "-q", "-m", "feat: add a").CombinedOutput(); err != nil {
+				t.Fatalf("git commit failed: %v\n%s", err, out)
+			}
+		}
+	}
+
+	// Amending the root commit describes its files together with the staged ones
+	parent, err := git.Parent("HEAD")
+	if err != nil {
+		t.Fatalf("Parent() error = %v", err)
+	}
+	d, err := Load(repo, Source{Kind: SourceStaged, Rev: parent})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if paths := d.Paths(); len(paths) != 2 || paths[0] != "a.go" || paths[1] != "b.go" {
+		t.Errorf("Load() paths = %q; want [a.go b.go]", paths)
+	}
+}
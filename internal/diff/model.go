@@ -0,0 +1,381 @@
+package diff
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Status is the kind of change made to a file, using the letters of git diff --name-status
+type Status byte
+
+const (
+	Added    Status = 'A'
+	Modified Status = 'M'
+	Deleted  Status = 'D'
+	Renamed  Status = 'R'
+	Copied   Status = 'C'
+)
+
+func (s Status) String() string {
+	return string(s)
+}
+
+// Diff is a parsed unified diff as produced by git diff
+type Diff struct {
+	Preamble []string // lines before the first file, e.g. the message of a mailed patch
+	Files    []*File
+}
+
+// File is the part of a diff that changes one file
+type File struct {
+	Status     Status
+	OldPath    string // "" for added files
+	NewPath    string // "" for deleted files
+	OldMode    string // file mode before the change, "" if unknown
+	NewMode    string // file mode after the change, "" if unknown
+	Similarity int    // similarity index of renames and copies in percent
+	Binary     bool
+	Header     []string // "diff --git" line and extended headers up to the first hunk
+	Hunks      []*Hunk
+	Added      int
+	Removed    int
+}
+
+// Hunk is one "@@" section of a file diff
+type Hunk struct {
+	OldStart int
+	OldLines int
+	NewStart int
+	NewLines int
+	Section  string   // text after the closing "@@", usually the enclosing function
+	Header   string   // the "@@" line itself
+	Lines    []string // content lines, each starting with ' ', '+', '-' or '\'
+	Added    int
+	Removed  int
+}
+
+// Parse parses the output of git diff. Text that is not part of a diff is kept in the
+// preamble or in the headers, so String returns the input unchanged.
+func Parse(text string) *Diff {
+	d := &Diff{}
+	if text == "" {
+		return d
+	}
+
+	var file *File
+	var hunk *Hunk
+	oldLeft, newLeft := 0, 0
+	for _, line := range strings.Split(strings.TrimSuffix(text, "\n"), "\n") {
+		switch {
+		case hunk != nil && (oldLeft > 0 || newLeft > 0):
+			// Inside a hunk the line counts decide, so removed lines such as "--- x" stay content
+			hunk.Lines = append(hunk.Lines, line)
+			switch {
+			case strings.HasPrefix(line, "+"):
+				hunk.Added++
+				newLeft--
+			case strings.HasPrefix(line, "-"):
+				hunk.Removed++
+				oldLeft--
+			case strings.HasPrefix(line, `\`):
+			default:
+				oldLeft--
+				newLeft--
+			}
+		case hunk != nil && strings.HasPrefix(line, `\`):
+			// "\ No newline at end of file" after the last line of a hunk
+			hunk.Lines = append(hunk.Lines, line)
+		case strings.HasPrefix(line, "diff --git "):
+			file, hunk = newFile(line), nil
+			d.Files = append(d.Files, file)
+		case strings.HasPrefix(line, "--- ") && (file == nil || hunk != nil):
+			// A patch without "diff --git" lines starts each file with its "---" line
+			file, hunk = &File{Status: Modified}, nil
+			file.parseHeader(line)
+			d.Files = append(d.Files, file)
+		case strings.HasPrefix(line, "@@ ") && file != nil:
+			hunk = parseHunkHeader(line)
+			oldLeft, newLeft = hunk.OldLines, hunk.NewLines
+			file.Hunks = append(file.Hunks, hunk)
+		case file == nil:
+			d.Preamble = append(d.Preamble, line)
+		case hunk != nil:
+			// Trailing text after the last hunk of a file, e.g. a signature
+			hunk.Lines = append(hunk.Lines, line)
+		default:
+			file.parseHeader(line)
+		}
+	}
+
+	for _, f := range d.Files {
+		for _, h := range f.Hunks {
+			f.Added += h.Added
+			f.Removed += h.Removed
+		}
+		if f.Status == Added {
+			f.OldPath = ""
+		} else if f.Status == Deleted {
+			f.NewPath = ""
+		}
+	}
+	return d
+}
+
+// newFile starts a file from its "diff --git a/<old> b/<new>" line
+func newFile(line string) *File {
+	f := &File{Status: Modified, Header: []string{line}}
+	paths := strings.TrimPrefix(line, "diff --git ")
+	if strings.HasPrefix(paths, `"`) {
+		if old, rest, ok := cutQuoted(paths); ok {
+			f.OldPath, f.NewPath = strings.TrimPrefix(old, "a/"), strings.TrimPrefix(unquote(strings.TrimPrefix(rest, " ")), "b/")
+			return f
+		}
+	}
+	// Unquoted paths are ambiguous when they contain " b/", the common case of an unchanged name is symmetric
+	if n := len(paths); n%2 == 1 && strings.HasPrefix(paths, "a/") && paths[n/2] == ' ' && paths[2:n/2] == paths[n/2+3:] {
+		f.OldPath, f.NewPath = paths[2:n/2], paths[n/2+3:]
+		return f
+	}
+	if i := strings.LastIndex(paths, " b/"); i >= 0 {
+		f.OldPath, f.NewPath = strings.TrimPrefix(unquote(paths[:i]), "a/"), unquote(paths[i+3:])
+		return f
+	}
+	f.OldPath, f.NewPath = paths, paths
+	return f
+}
+
+// parseHeader records an extended header line of git diff
+func (f *File) parseHeader(line string) {
+	f.Header = append(f.Header, line)
+
+	key, value := line, ""
+	for _, prefix := range []string{
+		"old mode ", "new mode ", "deleted file mode ", "new file mode ", "similarity index ",
+		"rename from ", "rename to ", "copy from ", "copy to ", "index ", "--- ", "+++ ",
+	} {
+		if strings.HasPrefix(line, prefix) {
+			key, value = prefix, strings.TrimPrefix(line, prefix)
+			break
+		}
+	}
+
+	switch key {
+	case "old mode ":
+		f.OldMode = value
+	case "new mode ":
+		f.NewMode = value
+	case "deleted file mode ":
+		f.Status, f.OldMode = Deleted, value
+	case "new file mode ":
+		f.Status, f.NewMode = Added, value
+	case "similarity index ":
+		f.Similarity, _ = strconv.Atoi(strings.TrimSuffix(value, "%"))
+	case "rename from ":
+		f.Status, f.OldPath = Renamed, unquote(value)
+	case "rename to ":
+		f.Status, f.NewPath = Renamed, unquote(value)
+	case "copy from ":
+		f.Status, f.OldPath = Copied, unquote(value)
+	case "copy to ":
+		f.Status, f.NewPath = Copied, unquote(value)
+	case "index ":
+		// "index abc123..def456 100644" carries the mode when it did not change
+		if fields := strings.Fields(value); len(fields) == 2 && f.OldMode == "" && f.NewMode == "" {
+			f.OldMode, f.NewMode = fields[1], fields[1]
+		}
+	case "--- ":
+		if p := headerPath(value); p == "/dev/null" {
+			f.Status = Added
+		} else {
+			f.OldPath = strings.TrimPrefix(p, "a/")
+		}
+	case "+++ ":
+		if p := headerPath(value); p == "/dev/null" {
+			f.Status = Deleted
+		} else {
+			f.NewPath = strings.TrimPrefix(p, "b/")
+		}
+	default:
+		if strings.HasPrefix(line, "Binary files ") || line == "GIT binary patch" {
+			f.Binary = true
+		}
+	}
+}
+
+// parseHunkHeader parses "@@ -<start>[,<lines>] +<start>[,<lines>] @@ [section]"
+func parseHunkHeader(line string) *Hunk {
+	h := &Hunk{Header: line, OldLines: 1, NewLines: 1}
+	rest := strings.TrimPrefix(line, "@@ ")
+	end := strings.Index(rest, " @@")
+	if end < 0 {
+		return h
+	}
+	h.Section = strings.TrimPrefix(rest[end+3:], " ")
+	for _, r := range strings.Fields(rest[:end]) {
+		start, lines := &h.OldStart, &h.OldLines
+		if strings.HasPrefix(r, "+") {
+			start, lines = &h.NewStart, &h.NewLines
+		}
+		r = r[1:]
+		if i := strings.Index(r, ","); i >= 0 {
+			*lines, _ = strconv.Atoi(r[i+1:])
+			r = r[:i]
+		}
+		*start, _ = strconv.Atoi(r)
+	}
+	return h
+}
+
+// headerPath returns the path of a "---" or "+++" line without the timestamp plain diff appends
+func headerPath(value string) string {
+	if strings.HasPrefix(value, `"`) {
+		if p, _, ok := cutQuoted(value); ok {
+			return p
+		}
+	}
+	if i := strings.Index(value, "\t"); i >= 0 {
+		value = value[:i]
+	}
+	return value
+}
+
+// cutQuoted splits a leading C-quoted string, as git writes unusual paths, from the rest of s
+func cutQuoted(s string) (string, string, bool) {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			p, err := strconv.Unquote(s[:i+1])
+			return p, s[i+1:], err == nil
+		}
+	}
+	return "", s, false
+}
+
+// unquote decodes a path that git quoted because of special characters
+func unquote(p string) string {
+	if q, rest, ok := cutQuoted(p); ok && rest == "" && strings.HasPrefix(p, `"`) {
+		return q
+	}
+	return p
+}
+
+// Path returns the path of the file after the change, or before it for deletions
+func (f *File) Path() string {
+	if f.NewPath != "" {
+		return f.NewPath
+	}
+	return f.OldPath
+}
+
+// ModeChanged reports whether the change alters the file mode, e.g. the executable bit
+func (f *File) ModeChanged() bool {
+	return f.Status == Modified && f.OldMode != "" && f.NewMode != "" && f.OldMode != f.NewMode
+}
+
+// Stat returns the change counts of the file in diffstat form, e.g. "+120 -40"
+func (f *File) Stat() string {
+	if f.Binary {
+		return "binary"
+	}
+	return fmt.Sprintf("+%d -%d", f.Added, f.Removed)
+}
+
+// Describe returns a one-line summary of the change in the style of git diff --name-status,
+// e.g. "R  old.go -> new.go (95%, +2 -1)"
+func (f *File) Describe() string {
+	name := f.Path()
+	if f.Status == Renamed || f.Status == Copied {
+		name = fmt.Sprintf("%s -> %s", f.OldPath, f.NewPath)
+	}
+	details := []string{}
+	if f.Similarity > 0 && (f.Status == Renamed || f.Status == Copied) {
+		details = append(details, fmt.Sprintf("%d%%", f.Similarity))
+	}
+	if f.ModeChanged() {
+		details = append(details, fmt.Sprintf("mode %s -> %s", f.OldMode, f.NewMode))
+	}
+	details = append(details, f.Stat())
+	return fmt.Sprintf("%s  %s (%s)", f.Status, name, strings.Join(details, ", "))
+}
+
+// String returns the file diff as text
+func (f *File) String() string {
+	var b strings.Builder
+	f.write(&b)
+	return b.String()
+}
+
+// write appends the file diff to b, one newline-terminated line at a time
+func (f *File) write(b *strings.Builder) {
+	for _, line := range f.Header {
+		b.WriteString(line + "\n")
+	}
+	for _, h := range f.Hunks {
+		b.WriteString(h.String())
+	}
+}
+
+// String returns the hunk as text
+func (h *Hunk) String() string {
+	var b strings.Builder
+	b.WriteString(h.Header + "\n")
+	for _, line := range h.Lines {
+		b.WriteString(line + "\n")
+	}
+	return b.String()
+}
+
+// String returns the diff as text, every line terminated by a newline
+func (d *Diff) String() string {
+	var b strings.Builder
+	for _, line := range d.Preamble {
+		b.WriteString(line + "\n")
+	}
+	for _, f := range d.Files {
+		f.write(&b)
+	}
+	return b.String()
+}
+
+// Stat returns the number of added and removed lines over all files
+func (d *Diff) Stat() (added, removed int) {
+	for _, f := range d.Files {
+		added += f.Added
+		removed += f.Removed
+	}
+	return added, removed
+}
+
+// Paths returns the path of every changed file, see File.Path
+func (d *Diff) Paths() []string {
+	paths := make([]string, 0, len(d.Files))
+	for _, f := range d.Files {
+		paths = append(paths, f.Path())
+	}
+	return paths
+}
+
+// Filter returns a diff with the files for which keep returns true
+func (d *Diff) Filter(keep func(f *File) bool) *Diff {
+	filtered := &Diff{Preamble: d.Preamble}
+	for _, f := range d.Files {
+		if keep(f) {
+			filtered.Files = append(filtered.Files, f)
+		}
+	}
+	return filtered
+}
+
+// Summary lists the changed files, one Describe line each, followed by the totals
+func (d *Diff) Summary() string {
+	var b strings.Builder
+	for _, f := range d.Files {
+		b.WriteString(f.Describe() + "\n")
+	}
+	added, removed := d.Stat()
+	fmt.Fprintf(&b, "%d files changed, %d insertions(+), %d deletions(-)\n", len(d.Files), added, removed)
+	return b.String()
+}
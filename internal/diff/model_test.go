@@ -0,0 +1,119 @@
+package diff
+
+import (
+	"testing"
+)
+
+const sampleDiff = `diff --git a/cmd/main.go b/cmd/main.go
+index 1111111..2222222 100644
+--- a/cmd/main.go
++++ b/cmd/main.go
+@@ -10,3 +10,4 @@ func main() {
+ 	args := os.Args
+--- not a header, a removed line starting with dashes
++	if len(args) < 2 {
++		usage()
+ 	}
+@@ -40 +41 @@ func usage() {
+-	fmt.Println("usage")
++	fmt.Println("usage: main <file>")
+\ No newline at end of file
+diff --git a/docs/old name.md b/docs/new name.md
+similarity index 92%
+rename from docs/old name.md
+rename to docs/new name.md
+index 3333333..4444444 100644
+--- a/docs/old name.md
++++ b/docs/new name.md
+@@ -1 +1 @@
+-# Old
++# New
+diff --git a/assets/logo.png b/assets/logo.png
+new file mode 100644
+index 0000000..5555555
+Binary files /dev/null and b/assets/logo.png differ
+diff --git a/scripts/build.sh b/scripts/build.sh
+old mode 100644
+new mode 100755
+diff --git a/legacy.go b/legacy.go
+deleted file mode 100644
+index 6666666..0000000
+--- a/legacy.go
++++ /dev/null
+@@ -1,2 +0,0 @@
+-package legacy
+-
+diff --git "a/caf\303\251.txt" "b/caf\303\251.txt"
+index 7777777..8888888 100644
+--- "a/caf\303\251.txt"
++++ "b/caf\303\251.txt"
+@@ -1 +1,2 @@
+ menu
++cr\303\250me
+`
+
+func TestParse(t *testing.T) {
+	d := Parse(sampleDiff)
+	if got := d.String(); got != sampleDiff {
+		t.Errorf("String() does not reproduce the input:\n%s", got)
+	}
+
+	tests := []struct {
+		status  Status
+		oldPath string
+		newPath string
+		hunks   int
+		stat    string
+	}{
+		{Modified, "cmd/main.go", "cmd/main.go", 2, "+3 -2"},
+		{Renamed, "docs/old name.md", "docs/new name.md", 1, "+1 -1"},
+		{Added, "", "assets/logo.png", 0, "binary"},
+		{Modified, "scripts/build.sh", "scripts/build.sh", 0, "+0 -0"},
+		{Deleted, "legacy.go", "", 1, "+0 -2"},
+		{Modified, "café.txt", "café.txt", 1, "+1 -0"},
+	}
+	if len(d.Files) != len(tests) {
+		t.Fatalf("Parse() returned %d files; want %d", len(d.Files), len(tests))
+	}
+	for i, tt := range tests {
+		f := d.Files[i]
+		if f.Status != tt.status || f.OldPath != tt.oldPath || f.NewPath != tt.newPath || len(f.Hunks) != tt.hunks || f.Stat() != tt.stat {
+			t.Errorf("File %d = %s %q -> %q, %d hunks, %s; want %s %q -> %q, %d hunks, %s", i,
+				f.Status, f.OldPath, f.NewPath, len(f.Hunks), f.Stat(), tt.status, tt.oldPath, tt.newPath, tt.hunks, tt.stat)
+		}
+	}
+
+	h := d.Files[0].Hunks[0]
+	if h.OldStart != 10 || h.OldLines != 3 || h.NewStart != 10 || h.NewLines != 4 || h.Section != "func main() {" {
+		t.Errorf("Unexpected hunk header %+v", h)
+	}
+	if h := d.Files[0].Hunks[1]; h.OldLines != 1 || h.NewStart != 41 || len(h.Lines) != 3 {
+		t.Errorf("Unexpected hunk with omitted line counts %+v", h)
+	}
+	if f := d.Files[1]; f.Similarity != 92 || f.Describe() != "R  docs/old name.md -> docs/new name.md (92%, +1 -1)" {
+		t.Errorf("Describe() = %q", f.Describe())
+	}
+	if f := d.Files[2]; !f.Binary || f.NewMode != "100644" {
+		t.Errorf("Unexpected binary file %+v", f)
+	}
+	if f := d.Files[3]; !f.ModeChanged() || f.Describe() != "M  scripts/build.sh (mode 100644 -> 100755, +0 -0)" {
+		t.Errorf("Describe() = %q", f.Describe())
+	}
+	if added, removed := d.Stat(); added != 5 || removed != 5 {
+		t.Errorf("Stat() = +%d -%d; want +5 -5", added, removed)
+	}
+}
+
+func TestParsePlainPatch(t *testing.T) {
+	patch := "Fix the greeting\n\n--- a/hello.txt\t2024-01-01 10:00:00\n+++ b/hello.txt\t2024-01-02 10:00:00\n@@ -1 +1 @@\n-helo\n+hello\n--- a/bye.txt\n+++ b/bye.txt\n@@ -1 +1 @@\n-by\n+bye\n"
+	d := Parse(patch)
+	if len(d.Preamble) != 2 || len(d.Files) != 2 {
+		t.Fatalf("Parse() = %d preamble lines, %d files; want 2 and 2", len(d.Preamble), len(d.Files))
+	}
+	if d.Files[0].Path() != "hello.txt" || d.Files[1].Path() != "bye.txt" || d.Files[1].Stat() != "+1 -1" {
+		t.Errorf("Unexpected files %q and %q", d.Files[0].Path(), d.Files[1].Path())
+	}
+	if d.String() != patch {
+		t.Errorf("String() does not reproduce the patch:\n%s", d.String())
+	}
+}
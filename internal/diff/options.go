@@ -0,0 +1,130 @@
+package diff
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"git-commit/internal/git"
+	"git-commit/internal/ignore"
+)
+
+// Formats of the changes inserted by a @diff directive
+const (
+	FormatFull  = "full"  // summary of the changed files followed by the patch, the default
+	FormatStat  = "stat"  // summary of the changed files only
+	FormatNames = "names" // one changed path per line
+)
+
+// Options select the slice of the changes a @diff directive inserts
+type Options struct {
+	Source       Source
+	Paths        []string // gitignore-style patterns the files must match, every file when empty
+	ContextLines int      // lines of context around each change, -1 for the configured value
+	Format       string
+}
+
+// ParseOptions parses the comma-separated arguments of @diff(...), e.g. "stat, path=src/api/**".
+// Arguments are stat, names-only, full, path=<pattern> (repeatable), context=<n>,
+// range=<from>..<to> and source=<spec> as accepted by ParseSource. src is used when
+// neither range nor source is given.
+func ParseOptions(args string, src Source) (Options, error) {
+	opts := Options{Source: src, ContextLines: -1, Format: FormatFull}
+	for _, arg := range strings.Split(args, ",") {
+		arg = strings.TrimSpace(arg)
+		key, value, hasValue := strings.Cut(arg, "=")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		switch {
+		case arg == "":
+			continue
+		case !hasValue && key == FormatStat:
+			opts.Format = FormatStat
+		case !hasValue && (key == "names-only" || key == FormatNames):
+			opts.Format = FormatNames
+		case !hasValue && key == FormatFull:
+			opts.Format = FormatFull
+		case key == "path" && value != "":
+			if _, err := ignore.ParsePattern(value); err != nil {
+				return opts, fmt.Errorf("invalid @diff argument %q: %v", arg, err)
+			}
+			opts.Paths = append(opts.Paths, value)
+		case key == "context":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return opts, fmt.Errorf("invalid @diff argument %q, context must be a number of lines", arg)
+			}
+			opts.ContextLines = n
+		case key == "range":
+			if !strings.Contains(value, "..") {
+				return opts, fmt.Errorf("invalid @diff argument %q, expected range=<from>..<to>", arg)
+			}
+			opts.Source = Source{Kind: SourceRange, Rev: value}
+		case key == "source":
+			parsed, err := ParseSource(value)
+			if err != nil {
+				return opts, err
+			}
+			if parsed.Kind == SourcePatch && src.Kind == SourcePatch {
+				// Standard input is read once, the directive describes the same patch as src
+				parsed = src
+			}
+			opts.Source = parsed
+		default:
+			return opts, fmt.Errorf("unknown @diff argument %q, expected stat, names-only, path=, context=, range= or source=", arg)
+		}
+	}
+	return opts, nil
+}
+
+// matchPaths keeps the files of d matching one of the patterns; like in ignore files
+// the last matching pattern wins and a leading "!" excludes files again
+func matchPaths(d *Diff, patterns []string) (*Diff, error) {
+	if len(patterns) == 0 {
+		return d, nil
+	}
+	matcher, err := ignore.NewMatcher(patterns)
+	if err != nil {
+		return nil, err
+	}
+	return d.Filter(func(f *File) bool {
+		return matcher.Match(f.Path(), false) || (f.OldPath != "" && matcher.Match(f.OldPath, false))
+	}), nil
+}
+
+// LoadWith returns the changes selected by opts without the files matched by the ignore files
+func LoadWith(repo *git.Repo, opts Options) (*Diff, error) {
+	contextLines := repo.Settings().Diff.ContextLines
+	if opts.ContextLines >= 0 {
+		contextLines = opts.ContextLines
+	}
+	d, err := load(repo, opts.Source, contextLines)
+	if err != nil {
+		return nil, err
+	}
+	return matchPaths(d, opts.Paths)
+}
+
+// GetDiffOutputWith returns the changes selected by opts without the files matched by the
+// ignore files, rendered for a prompt within the diff settings of the repository configuration
+func GetDiffOutputWith(repo *git.Repo, opts Options) (string, error) {
+	limits := repo.Settings().Diff
+	if opts.ContextLines >= 0 {
+		limits.ContextLines = opts.ContextLines
+	}
+
+	d, err := LoadWith(repo, opts)
+	if err != nil {
+		return "", err
+	}
+	if len(d.Files) == 0 {
+		return fmt.Sprintf("No changes in %s match %s.", opts.Source, strings.Join(opts.Paths, ", ")), nil
+	}
+
+	switch opts.Format {
+	case FormatStat:
+		return "Changed files:\n" + strings.TrimSuffix(d.Summary(), "\n"), nil
+	case FormatNames:
+		return strings.Join(d.Paths(), "\n"), nil
+	}
+	return Render(d, limits), nil
+}
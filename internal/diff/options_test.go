@@ -0,0 +1,66 @@
+package diff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseOptions(t *testing.T) {
+	staged := Source{Kind: SourceStaged}
+	tests := []struct {
+		args     string
+		expected Options
+		valid    bool
+	}{
+		{"", Options{Source: staged, ContextLines: -1, Format: FormatFull}, true},
+		{"stat", Options{Source: staged, ContextLines: -1, Format: FormatStat}, true},
+		{"names-only", Options{Source: staged, ContextLines: -1, Format: FormatNames}, true},
+		{"path=src/api/**", Options{Source: staged, Paths: []string{"src/api/**"}, ContextLines: -1, Format: FormatFull}, true},
+		{"path=*.go, path=!*_test.go, context=10", Options{Source: staged, Paths: []string{"*.go", "!*_test.go"}, ContextLines: 10, Format: FormatFull}, true},
+		{"range=origin/main..HEAD, stat", Options{Source: Source{Kind: SourceRange, Rev: "origin/main..HEAD"}, ContextLines: -1, Format: FormatStat}, true},
+		{"source=commit:HEAD", Options{Source: Source{Kind: SourceCommit, Rev: "HEAD"}, ContextLines: -1, Format: FormatFull}, true},
+		{"context=-1", Options{}, false},
+		{"context=lots", Options{}, false},
+		{"range=HEAD", Options{}, false},
+		{"source=bogus", Options{}, false},
+		{"path=", Options{}, false},
+		{"path=[z-a].go", Options{}, false},
+		{"colour", Options{}, false},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseOptions(tt.args, staged)
+		if (err == nil) != tt.valid {
+			t.Errorf("ParseOptions(%q) error = %v; want valid = %v", tt.args, err, tt.valid)
+			continue
+		}
+		if tt.valid && !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("ParseOptions(%q) = %+v; want %+v", tt.args, got, tt.expected)
+		}
+	}
+}
+
+func TestMatchPaths(t *testing.T) {
+	d := Parse(fileSection("src/api/handler.go", 1, 1) + fileSection("src/api/handler_test.go", 1, 1) +
+		fileSection("src/web/app.go", 1, 1) + fileSection("README.md", 1, 1))
+
+	tests := []struct {
+		patterns []string
+		expected []string
+	}{
+		{nil, []string{"src/api/handler.go", "src/api/handler_test.go", "src/web/app.go", "README.md"}},
+		{[]string{"src/api/**"}, []string{"src/api/handler.go", "src/api/handler_test.go"}},
+		{[]string{"*.go", "!*_test.go"}, []string{"src/api/handler.go", "src/web/app.go"}},
+		{[]string{"docs/"}, []string{}},
+	}
+
+	for _, tt := range tests {
+		got, err := matchPaths(d, tt.patterns)
+		if err != nil {
+			t.Fatalf("matchPaths(%q) error = %v", tt.patterns, err)
+		}
+		if !reflect.DeepEqual(got.Paths(), tt.expected) {
+			t.Errorf("matchPaths(%q) = %q; want %q", tt.patterns, got.Paths(), tt.expected)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package diff
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"git-commit/internal/git"
+)
+
+// Kinds of diff sources
+const (
+	SourceStaged   = "staged"   // the index against HEAD, the default
+	SourceUnstaged = "unstaged" // the working tree against the index
+	SourceRange    = "range"    // a revision range such as main..HEAD
+	SourceCommit   = "commit"   // the changes of one existing commit, e.g. to reword it
+	SourceStash    = "stash"    // a stash entry against the commit it was created on
+	SourcePatch    = "patch"    // a patch read from standard input or the reader given to PatchSource
+)
+
+// Source selects the changes a prompt describes. The zero value is the staged changes.
+type Source struct {
+	Kind  string
+	Rev   string // revision range, commit or stash entry; for staged changes the commit compared to, HEAD when ""
+	patch *patch // the patch of a SourcePatch source, shared by the copies of the source
+}
+
+// PatchSource returns a source describing the patch read from input, standard input when nil.
+// The patch is read on first use, once for the source and all its copies.
+func PatchSource(input io.Reader) Source {
+	if input == nil {
+		input = os.Stdin
+	}
+	return Source{Kind: SourcePatch, patch: &patch{input: input}}
+}
+
+// ParseSource parses a source specification as given to -diff: "staged", "unstaged",
+// a range such as "main..HEAD", "commit:<rev>", "stash" or "stash:<n>", and "-" for a patch on stdin
+func ParseSource(spec string) (Source, error) {
+	kind, rev, hasRev := strings.Cut(spec, ":")
+	switch {
+	case spec == "" || spec == SourceStaged:
+		return Source{Kind: SourceStaged}, nil
+	case spec == SourceUnstaged:
+		return Source{Kind: SourceUnstaged}, nil
+	case spec == "-" || spec == SourcePatch:
+		return PatchSource(nil), nil
+	case kind == SourceCommit && hasRev && rev != "":
+		return Source{Kind: SourceCommit, Rev: rev}, nil
+	case spec == SourceStash:
+		return Source{Kind: SourceStash, Rev: "stash@{0}"}, nil
+	case kind == SourceStash && hasRev && rev != "":
+		if !strings.HasPrefix(rev, "stash@{") {
+			rev = "stash@{" + rev + "}"
+		}
+		return Source{Kind: SourceStash, Rev: rev}, nil
+	case kind == SourceRange && hasRev && strings.Contains(rev, ".."):
+		return Source{Kind: SourceRange, Rev: rev}, nil
+	case strings.Contains(spec, ".."):
+		return Source{Kind: SourceRange, Rev: spec}, nil
+	}
+	return Source{}, fmt.Errorf("invalid diff source %q, expected staged, unstaged, <from>..<to>, commit:<rev>, stash[:<n>] or -", spec)
+}
+
+// String describes the source for messages, e.g. "commit abc123"
+func (s Source) String() string {
+	switch s.Kind {
+	case "", SourceStaged:
+		if s.Rev != "" {
+			return "staged changes against " + s.Rev
+		}
+		return "staged changes"
+	case SourceUnstaged:
+		return "unstaged changes"
+	case SourcePatch:
+		return "patch on standard input"
+	}
+	return s.Kind + " " + s.Rev
+}
+
+// gitArgs returns the git command listing the changes of the source, nil for patches
+func (s Source) gitArgs() []string {
+	switch s.Kind {
+	case "", SourceStaged:
+		if s.Rev != "" {
+			return []string{"diff", "--staged", s.Rev}
+		}
+		return []string{"diff", "--staged"}
+	case SourceUnstaged:
+		return []string{"diff"}
+	case SourceRange:
+		return []string{"diff", s.Rev}
+	case SourceCommit:
+		// Merge commits are described by their changes against the first parent
+		return []string{"show", "--format=", "--patch", "--diff-merges=first-parent", s.Rev}
+	case SourceStash:
+		return []string{"diff", s.Rev + "^1", s.Rev}
+	}
+	return nil
+}
+
+// Base returns the revision holding the files before the changes of the source, "" for the index.
+// The previous versions of unstaged changes are in the index, those of a patch are unknown.
+func (s Source) Base() (string, error) {
+	switch s.Kind {
+	case "", SourceStaged:
+		return orHEAD(s.Rev), nil
+	case SourceUnstaged:
+		return "", nil
+	case SourceCommit:
+		return s.Rev + "^", nil
+	case SourceStash:
+		return s.Rev + "^1", nil
+	case SourceRange:
+		if from, to, ok := strings.Cut(s.Rev, "..."); ok {
+			return git.MergeBase(orHEAD(from), orHEAD(to))
+		}
+		from, _, _ := strings.Cut(s.Rev, "..")
+		return orHEAD(from), nil
+	}
+	return "", fmt.Errorf("the previous versions of the files are not known for a %s", s)
+}
+
+// orHEAD returns rev, or HEAD for the empty side of a range such as "main.."
+func orHEAD(rev string) string {
+	if rev == "" {
+		return "HEAD"
+	}
+	return rev
+}
+
+// patch is the text of a patch, read once from its input
+type patch struct {
+	input io.Reader
+	once  sync.Once
+	text  string
+	err   error
+}
+
+// read returns the patch text, reading the input on the first call
+func (p *patch) read() (string, error) {
+	p.once.Do(func() {
+		data, err := io.ReadAll(p.input)
+		if err != nil {
+			p.err = fmt.Errorf("failed to read patch: %v", err)
+			return
+		}
+		p.text = string(data)
+	})
+	return p.text, p.err
+}
+
+// readPatch reads the patch text of a SourcePatch source
+func (s Source) readPatch() (string, error) {
+	if s.patch == nil {
+		// A Source literal has no patch of its own, it reads standard input
+		return PatchSource(nil).patch.read()
+	}
+	return s.patch.read()
+}
@@ -0,0 +1,117 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseSource(t *testing.T) {
+	tests := []struct {
+		spec     string
+		expected Source
+		valid    bool
+	}{
+		{"", Source{Kind: SourceStaged}, true},
+		{"staged", Source{Kind: SourceStaged}, true},
+		{"unstaged", Source{Kind: SourceUnstaged}, true},
+		{"main..HEAD", Source{Kind: SourceRange, Rev: "main..HEAD"}, true},
+		{"origin/main...feature", Source{Kind: SourceRange, Rev: "origin/main...feature"}, true},
+		{"range:v1.0..v1.1", Source{Kind: SourceRange, Rev: "v1.0..v1.1"}, true},
+		{"commit:HEAD~2", Source{Kind: SourceCommit, Rev: "HEAD~2"}, true},
+		{"stash", Source{Kind: SourceStash, Rev: "stash@{0}"}, true},
+		{"stash:2", Source{Kind: SourceStash, Rev: "stash@{2}"}, true},
+		{"stash:stash@{1}", Source{Kind: SourceStash, Rev: "stash@{1}"}, true},
+		{"-", Source{Kind: SourcePatch}, true},
+		{"patch", Source{Kind: SourcePatch}, true},
+		{"commit:", Source{}, false},
+		{"range:HEAD", Source{}, false},
+		{"HEAD", Source{}, false},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseSource(tt.spec)
+		if (err == nil) != tt.valid {
+			t.Errorf("ParseSource(%q) error = %v; want valid = %v", tt.spec, err, tt.valid)
+			continue
+		}
+		if got.Kind == SourcePatch {
+			if got.patch == nil {
+				t.Errorf("ParseSource(%q) has no patch to read", tt.spec)
+			}
+			got.patch = nil
+		}
+		if got != tt.expected {
+			t.Errorf("ParseSource(%q) = %+v; want %+v", tt.spec, got, tt.expected)
+		}
+	}
+}
+
+func TestSourceGitArgs(t *testing.T) {
+	tests := []struct {
+		src      Source
+		expected string
+	}{
+		{Source{}, "diff --staged"},
+		{Source{Kind: SourceStaged, Rev: "HEAD^"}, "diff --staged HEAD^"},
+		{Source{Kind: SourceUnstaged}, "diff"},
+		{Source{Kind: SourceRange, Rev: "main..HEAD"}, "diff main..HEAD"},
+		{Source{Kind: SourceCommit, Rev: "abc123"}, "show --format= --patch --diff-merges=first-parent abc123"},
+		{Source{Kind: SourceStash, Rev: "stash@{1}"}, "diff stash@{1}^1 stash@{1}"},
+	}
+
+	for _, tt := range tests {
+		if got := strings.Join(tt.src.gitArgs(), " "); got != tt.expected {
+			t.Errorf("gitArgs() of %s = %q; want %q", tt.src, got, tt.expected)
+		}
+	}
+}
+
+func TestSourceBase(t *testing.T) {
+	tests := []struct {
+		src      Source
+		expected string
+	}{
+		{Source{}, "HEAD"},
+		{Source{Kind: SourceStaged, Rev: "HEAD^"}, "HEAD^"},
+		{Source{Kind: SourceUnstaged}, ""},
+		{Source{Kind: SourceRange, Rev: "main..feature"}, "main"},
+		{Source{Kind: SourceRange, Rev: "..feature"}, "HEAD"},
+		{Source{Kind: SourceCommit, Rev: "abc123"}, "abc123^"},
+		{Source{Kind: SourceStash, Rev: "stash@{1}"}, "stash@{1}^1"},
+	}
+
+	for _, tt := range tests {
+		if got, err := tt.src.Base(); err != nil || got != tt.expected {
+			t.Errorf("Base() of %s = %q, %v; want %q", tt.src, got, err, tt.expected)
+		}
+	}
+	if _, err := (Source{Kind: SourcePatch}).Base(); err == nil {
+		t.Error("Base() of a patch should fail")
+	}
+}
+
+func TestPatchSourceReadsOnce(t *testing.T) {
+	input := strings.NewReader(fileSection("a.go", 1, 1))
+	src := PatchSource(input)
+	copied := src
+	first, err := src.readPatch()
+	if err != nil || first == "" {
+		t.Fatalf("readPatch() = %q, %v; want the patch", first, err)
+	}
+	if again, err := copied.readPatch(); err != nil || again != first {
+		t.Errorf("readPatch() of a copy = %q, %v; want %q", again, err, first)
+	}
+
+	opts, err := ParseOptions("source=-", src)
+	if err != nil {
+		t.Fatalf("ParseOptions() error = %v", err)
+	}
+	if text, _ := opts.Source.readPatch(); text != first {
+		t.Errorf("readPatch() of @diff(source=-) = %q; want the patch of src %q", text, first)
+	}
+
+	// Another source over the same, now drained, reader does not see the earlier text
+	if text, _ := PatchSource(input).readPatch(); text != "" {
+		t.Errorf("readPatch() of a new source = %q; want \"\"", text)
+	}
+}
@@ -0,0 +1,43 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrNotARepository is returned when there is no git working tree to run in
+var ErrNotARepository = errors.New("not a git repository")
+
+// ErrGitFailed matches every *CommandError, for callers that only care that git failed
+var ErrGitFailed = errors.New("git command failed")
+
+// CommandError is a git command that failed, with what it wrote to stderr
+type CommandError struct {
+	Args   []string // arguments of git, e.g. ["diff", "--staged"]
+	Err    error    // error of running the command, usually an *exec.ExitError
+	Stderr string
+}
+
+func (e *CommandError) Error() string {
+	msg := fmt.Sprintf("git %s: %v", e.Args[0], e.Err)
+	if stderr := strings.TrimSpace(e.Stderr); stderr != "" {
+		msg += "\n" + stderr
+	}
+	return msg
+}
+
+func (e *CommandError) Unwrap() error {
+	return e.Err
+}
+
+// Is matches ErrGitFailed, and ErrNotARepository when git refused to run outside a repository
+func (e *CommandError) Is(target error) bool {
+	switch target {
+	case ErrGitFailed:
+		return true
+	case ErrNotARepository:
+		return strings.Contains(e.Stderr, "not a git repository")
+	}
+	return false
+}
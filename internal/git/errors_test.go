@@ -0,0 +1,44 @@
+package git
+
+import (
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestCommandError(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	for _, key := range []string{"GIT_AUTHOR_NAME", "GIT_COMMITTER_NAME"} {
+		t.Setenv(key, "Test")
+	}
+	for _, key := range []string{"GIT_AUTHOR_EMAIL", "GIT_COMMITTER_EMAIL"} {
+		t.Setenv(key, "test@example.com")
+	}
+	dir := t.TempDir()
+	t.Setenv("GIT_CEILING_DIRECTORIES", dir)
+
+	_, err := runIn(dir, "rev-parse", "HEAD")
+	var cmdErr *CommandError
+	if !errors.As(err, &cmdErr) {
+		t.Fatalf("runIn() error = %v; want *CommandError", err)
+	}
+	if !errors.Is(err, ErrGitFailed) || !errors.Is(err, ErrNotARepository) {
+		t.Errorf("runIn() error = %v; want ErrGitFailed and ErrNotARepository", err)
+	}
+	if !strings.HasPrefix(err.Error(), "git rev-parse: exit status") || !strings.Contains(err.Error(), "not a git repository") {
+		t.Errorf("Error() = %q; want the command and its stderr", err.Error())
+	}
+
+	if _, err := OpenRepo(dir); !errors.Is(err, ErrNotARepository) {
+		t.Errorf("OpenRepo() error = %v; want ErrNotARepository", err)
+	}
+
+	root := newTestRepo(t, dir, "repo")
+	_, err = runIn(root, "show", "HEAD:missing.txt")
+	if !errors.Is(err, ErrGitFailed) || errors.Is(err, ErrNotARepository) {
+		t.Errorf("runIn() error = %v; want ErrGitFailed only", err)
+	}
+}
@@ -1,124 +1,330 @@
 package git
 
 import (
-	"bufio"
 	"bytes"
+	"errors"
 	"fmt"
-	"os"
 	"os/exec"
 	"strings"
 
-	"git-commit/pkg/utils"
+	"git-commit/internal/ignore"
 )
 
-// parseGitDiffIgnore reads the ignore file from .git-commit and returns a list of patterns to ignore
-func ParseGitDiffIgnore() ([]string, error) {
-	// Check if the ignore file exists in the .git-commit folder
-	if _, err := os.Stat(".git-commit/ignore"); os.IsNotExist(err) {
-		// If the file doesn't exist, return an empty list
-		return []string{}, nil
-	}
+// GetStagedFiles gets the list of files added to staged
+func GetStagedFiles() ([]string, error) {
+	return GetDiffFiles([]string{"diff", "--staged"})
+}
 
-	// Open the file for reading
-	file, err := os.Open(".git-commit/ignore")
+// GetDiffFiles returns the root-relative paths changed by a diff command such as "diff --staged"
+func GetDiffFiles(diffArgs []string) ([]string, error) {
+	// -z keeps names with non-ASCII or special characters as they are instead of C-quoting them
+	output, err := run(append(append([]string{}, diffArgs...), "--name-only", "-z")...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open file .git-commit/ignore: %v", err)
-	}
-	defer file.Close()
-
-	var patterns []string
-	scanner := bufio.NewScanner(file)
-	
-	// Read the file line by line
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		// Skip empty lines and comments
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
+		return nil, fmt.Errorf("error getting changed files list: %w", err)
+	}
+
+	return splitNUL(output), nil
+}
+
+// splitNUL splits the NUL-terminated names printed by git commands run with -z
+func splitNUL(output string) []string {
+	files := []string{}
+	for _, file := range strings.Split(output, "\x00") {
+		if file != "" {
+			files = append(files, file)
 		}
-		patterns = append(patterns, line)
 	}
+	return files
+}
 
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("error reading file .git-commit/ignore: %v", err)
+// GetDiff runs a diff command such as "diff --staged" or "show <rev>" with the specified files excluded.
+// contextLines sets the lines of context around each change, a negative value keeps git's default.
+func GetDiff(diffArgs []string, excludedFiles []string, contextLines int) (string, error) {
+	args := append([]string{}, diffArgs...)
+	if contextLines >= 0 {
+		args = append(args, fmt.Sprintf("--unified=%d", contextLines))
+	}
+	if len(excludedFiles) > 0 {
+		// Paths from --name-only -z are unquoted and relative to the repository root, so anchor them
+		// with "top" and match them literally so names containing glob characters are not expanded
+		args = append(args, "--")
+		for _, file := range excludedFiles {
+			args = append(args, ":(top,exclude,literal)"+file)
+		}
+	}
+
+	output, err := run(args...)
+	if err != nil {
+		return "", fmt.Errorf("error getting diff: %w", err)
 	}
 
-	return patterns, nil
+	return output, nil
 }
 
+// LoadIgnore builds the layered ignore matcher for the repository from the global,
+// repository and per-directory ignore files that apply to the given root-relative paths
+func LoadIgnore(repo *Repo, files []string) (*ignore.Matcher, error) {
+	return ignore.Load(repo.Path(), files)
+}
 
-// GetStagedFiles gets the list of files added to staged
-func GetStagedFiles() ([]string, error) {
-	cmd := exec.Command("git", "diff", "--staged", "--name-only")
-	var stdout, stderr bytes.Buffer
+// GetIgnoredFiles returns the root-relative files excluded by the layered ignore files
+func GetIgnoredFiles(repo *Repo, files []string) ([]string, error) {
+	matcher, err := LoadIgnore(repo, files)
+	if err != nil {
+		return nil, err
+	}
+
+	var ignoredFiles []string
+	for _, file := range files {
+		if matcher.Match(file, false) {
+			ignoredFiles = append(ignoredFiles, file)
+		}
+	}
+	return ignoredFiles, nil
+}
+
+// IsInsideWorkTree reports whether the current directory is inside a git working tree
+func IsInsideWorkTree() bool {
+	cmd := exec.Command("git", "rev-parse", "--is-inside-work-tree")
+	var stdout bytes.Buffer
 	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-	err := cmd.Run()
+	if err := cmd.Run(); err != nil {
+		return false
+	}
 
+	return strings.TrimSpace(stdout.String()) == "true"
+}
+
+// Version returns the version string reported by the git binary
+func Version() (string, error) {
+	output, err := run("--version")
 	if err != nil {
-		return nil, fmt.Errorf("error getting staged files list: %v\n%s", err, stderr.String())
+		return "", fmt.Errorf("error running git --version: %w", err)
 	}
 
-	output := strings.TrimSpace(stdout.String())
-	if output == "" {
-		return []string{}, nil
+	return strings.TrimSpace(output), nil
+}
+
+// GitPath resolves a path inside the git directory, such as COMMIT_EDITMSG or hooks
+func GitPath(name string) (string, error) {
+	output, err := run("rev-parse", "--git-path", name)
+	if err != nil {
+		return "", fmt.Errorf("error resolving git path %s: %w", name, err)
 	}
 
-	// Split the output into lines
-	files := strings.Split(output, "\n")
-	return files, nil
+	return strings.TrimSpace(output), nil
 }
 
-// GetFilesToIgnore returns the list of files that should be ignored
-func GetFilesToIgnore(patterns []string, files []string) []string {
-	var ignoredFiles []string
-	
-	for _, file := range files {
-		for _, pattern := range patterns {
-			if utils.GlobMatch(pattern, file) {
-				ignoredFiles = append(ignoredFiles, file)
-				break // File matched the pattern, no need to check further
-			}
-		}
+// CurrentBranch returns the name of the checked out branch, or "" on a detached HEAD
+func CurrentBranch() (string, error) {
+	cmd := exec.Command("git", "symbolic-ref", "--quiet", "--short", "HEAD")
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	if err := cmd.Run(); err != nil {
+		// symbolic-ref fails on a detached HEAD
+		return "", nil
 	}
-	
-	return ignoredFiles
+
+	return strings.TrimSpace(stdout.String()), nil
 }
 
-// RemoveFilesFromStaged removes the specified files from staged
-func RemoveFilesFromStaged(files []string) error {
-	if len(files) == 0 {
-		return nil
+// BranchExists reports whether a local branch with the given name exists
+func BranchExists(name string) bool {
+	cmd := exec.Command("git", "show-ref", "--verify", "--quiet", "refs/heads/"+name)
+	return cmd.Run() == nil
+}
+
+// CreateBranch creates a new branch from HEAD and switches to it, keeping staged changes
+func CreateBranch(name string) error {
+	if BranchExists(name) {
+		return fmt.Errorf("branch '%s' already exists", name)
 	}
-	
-	args := append([]string{"reset", "--"}, files...)
-	cmd := exec.Command("git", args...)
-	var stderr bytes.Buffer
-	cmd.Stderr = &stderr
-	err := cmd.Run()
-	
-	if err != nil {
-		return fmt.Errorf("error removing files from staged: %v\n%s", err, stderr.String())
+
+	if _, err := run("checkout", "-b", name); err != nil {
+		return fmt.Errorf("error creating branch %s: %w", name, err)
 	}
-	
+
 	return nil
 }
 
-// AddFilesToStaged adds the specified files to staged
-func AddFilesToStaged(files []string) error {
-	if len(files) == 0 {
-		return nil
+// CommitOptions configures Commit, mirroring the options of "git commit"
+type CommitOptions struct {
+	Message     string // message text, used when MessageFile is ""
+	MessageFile string // file holding the message, "-F <file>"
+	Amend       bool   // replace the tip of the current branch, keeping its message when none is given
+	Signoff     bool   // add a Signed-off-by trailer
+	Sign        bool   // sign the commit with GPG or SSH, as configured by gpg.format
+	SignKey     string // key to sign with, implies Sign
+	NoSign      bool   // do not sign, even when commit.gpgSign is set
+	Author      string // author override, "Name <email>"
+	NoVerify    bool   // skip the pre-commit and commit-msg hooks
+}
+
+// args returns the "git commit" arguments of the options, reading a Message from stdin
+func (o CommitOptions) args() ([]string, error) {
+	args := []string{"commit"}
+	switch {
+	case o.MessageFile != "":
+		args = append(args, "-F", o.MessageFile)
+	case o.Message != "":
+		args = append(args, "-F", "-")
+	case o.Amend:
+		args = append(args, "--no-edit")
+	default:
+		return nil, fmt.Errorf("no commit message given")
+	}
+	if o.Amend {
+		args = append(args, "--amend")
+	}
+	if o.Signoff {
+		args = append(args, "--signoff")
+	}
+	switch {
+	case (o.Sign || o.SignKey != "") && o.NoSign:
+		return nil, fmt.Errorf("cannot both sign and not sign the commit")
+	case o.SignKey != "":
+		args = append(args, "--gpg-sign="+o.SignKey)
+	case o.Sign:
+		args = append(args, "--gpg-sign")
+	case o.NoSign:
+		args = append(args, "--no-gpg-sign")
+	}
+	if o.Author != "" {
+		args = append(args, "--author="+o.Author)
 	}
-	
-	args := append([]string{"add", "--"}, files...)
+	if o.NoVerify {
+		args = append(args, "--no-verify")
+	}
+	return args, nil
+}
+
+// Commit records the staged changes as "git commit" does with opts and returns the summary git
+// prints, e.g. "[main 1a2b3c4] feat: add x". Hook failures are *CommandError values with the
+// output of the hook.
+func Commit(opts CommitOptions) (string, error) {
+	args, err := opts.args()
+	if err != nil {
+		return "", err
+	}
+	output, err := runInput(opts.Message, args...)
+	if err != nil {
+		return "", fmt.Errorf("error creating commit: %w", err)
+	}
+
+	return strings.TrimSpace(output), nil
+}
+
+// ConfigValue returns the value of a git config key, or "" if it is not set
+func ConfigValue(key string) (string, error) {
+	output, err := run("config", "--get", key)
+	if err != nil {
+		// git config exits with status 1 when the key is not set
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return "", nil
+		}
+		return "", fmt.Errorf("error reading git config %s: %w", key, err)
+	}
+
+	return strings.TrimSpace(output), nil
+}
+
+// run runs a git command and returns its output, or a *CommandError with the command's stderr
+func run(args ...string) (string, error) {
+	return runIn("", args...)
+}
+
+// runIn is run in the directory dir, the current directory when dir is ""
+func runIn(dir string, args ...string) (string, error) {
+	return execute(dir, "", args)
+}
+
+// runInput is run with input on stdin
+func runInput(input string, args ...string) (string, error) {
+	return execute("", input, args)
+}
+
+// execute runs a git command in dir with input on stdin
+func execute(dir, input string, args []string) (string, error) {
 	cmd := exec.Command("git", args...)
-	var stderr bytes.Buffer
+	cmd.Dir = dir
+	if input != "" {
+		cmd.Stdin = strings.NewReader(input)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 	err := cmd.Run()
-	
+
 	if err != nil {
-		return fmt.Errorf("error adding files to staged: %v\n%s", err, stderr.String())
+		return "", &CommandError{Args: args, Err: err, Stderr: stderr.String()}
 	}
-	
-	return nil
-}
\ No newline at end of file
+
+	return stdout.String(), nil
+}
+
+// ShowFile returns the content of a root-relative path at a revision, or in the index when rev is ""
+func ShowFile(rev, path string) (string, error) {
+	return run("show", rev+":"+path)
+}
+
+// MergeBase returns the best common ancestor of two revisions, as used by "a...b" ranges
+func MergeBase(a, b string) (string, error) {
+	out, err := run("merge-base", a, b)
+	return strings.TrimSpace(out), err
+}
+
+// Parent returns the first parent of the commit rev as "<rev>^", or the empty tree when rev is a
+// root commit, so the changes of any commit can be diffed against the result
+func Parent(rev string) (string, error) {
+	if _, err := run("rev-parse", "--verify", "--quiet", rev+"^"); err == nil {
+		return rev + "^", nil
+	}
+	if _, err := run("rev-parse", "--verify", "--quiet", rev+"^{commit}"); err != nil {
+		return "", fmt.Errorf("%s is not a commit: %w", rev, err)
+	}
+	return EmptyTree()
+}
+
+// EmptyTree returns the hash of the empty tree in the object format of the repository
+func EmptyTree() (string, error) {
+	out, err := run("hash-object", "-t", "tree", "--stdin")
+	return strings.TrimSpace(out), err
+}
+
+// Log returns the last n commits of HEAD, one "<hash> <date> <author> <subject>" line each
+func Log(n int) (string, error) {
+	return run("log", fmt.Sprintf("--max-count=%d", n), "--date=short", "--format=%h %ad %an %s")
+}
+
+// Blame returns the blame of count lines of path at a revision, starting at line start.
+// path is relative to root, the top directory of the working tree, since blame takes no pathspec.
+func Blame(root, rev, path string, start, count int) (string, error) {
+	return runIn(root, "blame", "--date=short", "-L", fmt.Sprintf("%d,+%d", start, count), rev, "--", path)
+}
+
+// BlameContents is Blame of contents, a version of path that is not committed such as the one in
+// the index, with the lines that differ from HEAD attributed to "Not Committed Yet"
+func BlameContents(root, path, contents string, start, count int) (string, error) {
+	return execute(root, contents, []string{"blame", "--date=short", "--contents", "-", "-L", fmt.Sprintf("%d,+%d", start, count), "--", path})
+}
+
+// ListFiles returns the root-relative paths of the tracked files and of the untracked files
+// that are not ignored by .gitignore
+func ListFiles() ([]string, error) {
+	out, err := run("ls-files", "-z", "--cached", "--others", "--exclude-standard", "--full-name", "--", ":/")
+	if err != nil {
+		return nil, err
+	}
+	return splitNUL(out), nil
+}
+
+// Editor returns the editor command git uses for commit messages: $GIT_EDITOR, core.editor,
+// $VISUAL, $EDITOR or git's default, in that order
+func Editor() (string, error) {
+	output, err := run("var", "GIT_EDITOR")
+	if err != nil {
+		return "", fmt.Errorf("error finding the git editor: %w", err)
+	}
+	return strings.TrimSpace(output), nil
+}
@@ -0,0 +1,142 @@
+package git
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCommitOptionsArgs(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     CommitOptions
+		expected string
+	}{
+		{"Message", CommitOptions{Message: "feat: add x"}, "commit -F -"},
+		{"Message file", CommitOptions{MessageFile: "msg.txt", Message: "ignored"}, "commit -F msg.txt"},
+		{"Amend keeping the message", CommitOptions{Amend: true}, "commit --no-edit --amend"},
+		{"Everything", CommitOptions{Message: "x", Amend: true, Signoff: true, Sign: true, Author: "Ada <ada@example.com>", NoVerify: true},
+			"commit -F - --amend --signoff --gpg-sign --author=Ada <ada@example.com> --no-verify"},
+		{"Signing key", CommitOptions{Message: "x", SignKey: "ABCD1234"}, "commit -F - --gpg-sign=ABCD1234"},
+		{"No signing", CommitOptions{Message: "x", NoSign: true}, "commit -F - --no-gpg-sign"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args, err := tt.opts.args()
+			if err != nil {
+				t.Fatalf("args() error = %v", err)
+			}
+			if got := strings.Join(args, " "); got != tt.expected {
+				t.Errorf("args() = %q; want %q", got, tt.expected)
+			}
+		})
+	}
+
+	for _, opts := range []CommitOptions{{}, {Message: "x", Sign: true, NoSign: true}} {
+		if _, err := opts.args(); err == nil {
+			t.Errorf("args() of %+v should fail", opts)
+		}
+	}
+}
+
+func TestCommit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	for _, key := range []string{"GIT_AUTHOR_NAME", "GIT_COMMITTER_NAME"} {
+		t.Setenv(key, "Test")
+	}
+	for _, key := range []string{"GIT_AUTHOR_EMAIL", "GIT_COMMITTER_EMAIL"} {
+		t.Setenv(key, "test@example.com")
+	}
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+
+	root := newTestRepo(t, t.TempDir(), "repo")
+	wd, _ := os.Getwd()
+	if err := os.Chdir(root); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+	log := func() string {
+		out, err := exec.Command("git", "log", "-1", "--format=%an <%ae>%n%B").Output()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return strings.TrimSpace(string(out))
+	}
+
+	if err := os.WriteFile("a.txt", []byte("a"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, root, "add", "a.txt")
+	summary, err := Commit(CommitOptions{Message: "feat: add a\n\nBody.\n", Signoff: true, Author: "Ada <ada@example.com>"})
+	if err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+	if !strings.Contains(summary, "feat: add a") {
+		t.Errorf("Commit() summary = %q", summary)
+	}
+	if got := log(); got != "Ada <ada@example.com>\nfeat: add a\n\nBody.\n\nSigned-off-by: Test <test@example.com>" {
+		t.Errorf("Commit created %q", got)
+	}
+
+	file := filepath.Join(t.TempDir(), "message")
+	if err := os.WriteFile(file, []byte("fix: reword a\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Commit(CommitOptions{MessageFile: file, Amend: true}); err != nil {
+		t.Fatalf("Commit() amend error = %v", err)
+	}
+	if got := log(); got != "Ada <ada@example.com>\nfix: reword a" {
+		t.Errorf("Amended commit is %q", got)
+	}
+
+	// A rejecting commit-msg hook fails the commit unless it is skipped
+	hook := filepath.Join(root, ".git", "hooks", "commit-msg")
+	if err := os.WriteFile(hook, []byte("#!/bin/sh\necho rejected >&2\nexit 1\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	_, err = Commit(CommitOptions{Message: "chore: empty", Amend: true})
+	if !errors.Is(err, ErrGitFailed) || !strings.Contains(err.Error(), "rejected") {
+		t.Errorf("Commit() with a rejecting hook error = %v", err)
+	}
+	if _, err := Commit(CommitOptions{Message: "chore: skip hooks", Amend: true, NoVerify: true}); err != nil {
+		t.Errorf("Commit() with NoVerify error = %v", err)
+	}
+}
+
+func TestParent(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	for _, key := range []string{"GIT_AUTHOR_NAME", "GIT_COMMITTER_NAME"} {
+		t.Setenv(key, "Test")
+	}
+	for _, key := range []string{"GIT_AUTHOR_EMAIL", "GIT_COMMITTER_EMAIL"} {
+		t.Setenv(key, "test@example.com")
+	}
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+
+	root := newTestRepo(t, t.TempDir(), "repo")
+	wd, _ := os.Getwd()
+	if err := os.Chdir(root); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	// The only commit is a root commit, its changes are diffed against the empty tree
+	if got, err := Parent("HEAD"); err != nil || got != "4b825dc642cb6eb9a060e54bf8d69288fbee4904" {
+		t.Errorf("Parent(root commit) = %q, %v; want the empty tree", got, err)
+	}
+	runGit(t, root, "commit", "-q", "--allow-empty", "-m", "second")
+	if got, err := Parent("HEAD"); err != nil || got != "HEAD^" {
+		t.Errorf("Parent(HEAD) = %q, %v; want HEAD^", got, err)
+	}
+	if _, err := Parent("no-such-branch"); !errors.Is(err, ErrGitFailed) {
+		t.Errorf("Parent(no-such-branch) error = %v; want ErrGitFailed", err)
+	}
+}
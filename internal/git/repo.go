@@ -0,0 +1,123 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"git-commit/internal/config"
+)
+
+// ConfigDir is the folder holding git-commit configuration, relative to the repository root
+const ConfigDir = ".git-commit"
+
+// Repo describes the repository git-commit runs in. Loaders resolve configuration and
+// context files against Root, so the tool behaves the same from any subdirectory.
+// A nil *Repo stands for "no repository" and resolves paths against the working directory.
+type Repo struct {
+	Root         string // absolute path of the working tree root
+	GitDir       string // git directory of this working tree
+	CommonDir    string // git directory shared by all worktrees of the repository
+	Superproject string // working tree root of the parent repository when Root is a submodule
+
+	Config *config.Config // merged config.yaml settings, defaults when nil
+}
+
+// OpenRepo discovers the repository containing dir, or the working directory if dir is empty
+func OpenRepo(dir string) (*Repo, error) {
+	if dir == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+		dir = wd
+	}
+
+	cmd := exec.Command("git", "rev-parse", "--show-toplevel", "--absolute-git-dir", "--git-common-dir", "--show-superproject-working-tree")
+	cmd.Dir = dir
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v\n%s", ErrNotARepository, err, stderr.String())
+	}
+
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	if len(lines) < 3 || lines[0] == "" {
+		// A bare repository has no working tree
+		return nil, fmt.Errorf("%w: no working tree in %s", ErrNotARepository, dir)
+	}
+
+	repo := &Repo{Root: lines[0], GitDir: lines[1], CommonDir: lines[2]}
+	// --git-common-dir is relative to the directory git ran in
+	if !filepath.IsAbs(repo.CommonDir) {
+		repo.CommonDir = filepath.Join(dir, repo.CommonDir)
+	}
+	repo.CommonDir = filepath.Clean(repo.CommonDir)
+	if len(lines) > 3 {
+		repo.Superproject = lines[3]
+	}
+
+	return repo, nil
+}
+
+// Settings returns the configuration of the repository, or the defaults when none was loaded
+func (r *Repo) Settings() *config.Config {
+	if r == nil || r.Config == nil {
+		return config.Default()
+	}
+	return r.Config
+}
+
+// IsWorktree reports whether the working tree is a linked worktree created by "git worktree add"
+func (r *Repo) IsWorktree() bool {
+	return r != nil && r.GitDir != r.CommonDir
+}
+
+// IsSubmodule reports whether the repository is a submodule of another repository
+func (r *Repo) IsSubmodule() bool {
+	return r != nil && r.Superproject != ""
+}
+
+// Path joins path elements onto the repository root
+func (r *Repo) Path(elem ...string) string {
+	if r == nil {
+		return filepath.Join(elem...)
+	}
+	return filepath.Join(append([]string{r.Root}, elem...)...)
+}
+
+// ConfigPath joins path elements onto the .git-commit folder of the repository
+func (r *Repo) ConfigPath(elem ...string) string {
+	return r.Path(append([]string{ConfigDir}, elem...)...)
+}
+
+// Rel converts a path into a slash-separated path relative to the repository root.
+// Relative paths are taken relative to the working directory, like git does.
+func (r *Repo) Rel(path string) (string, error) {
+	if r == nil {
+		return "", fmt.Errorf("path %q is not inside a git repository", path)
+	}
+	abs := path
+	if !filepath.IsAbs(abs) {
+		wd, err := os.Getwd()
+		if err != nil {
+			return "", err
+		}
+		// git reports the root with symlinks resolved, so resolve the working directory too
+		if resolved, err := filepath.EvalSymlinks(wd); err == nil {
+			wd = resolved
+		}
+		abs = filepath.Join(wd, path)
+	}
+	rel, err := filepath.Rel(r.Root, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("path %q is outside the repository", path)
+	}
+	return filepath.ToSlash(rel), nil
+}
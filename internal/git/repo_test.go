@@ -0,0 +1,102 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// runGit runs a git command in dir and fails the test on error
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v failed: %v\n%s", args, err, out)
+	}
+}
+
+// newTestRepo creates a repository with one commit and returns its resolved root
+func newTestRepo(t *testing.T, parent, name string) string {
+	t.Helper()
+	root := filepath.Join(parent, name)
+	runGit(t, parent, "init", "-q", name)
+	runGit(t, root, "commit", "-q", "--allow-empty", "-m", "init")
+	return root
+}
+
+func TestOpenRepo(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	for _, key := range []string{"GIT_AUTHOR_NAME", "GIT_COMMITTER_NAME"} {
+		t.Setenv(key, "Test")
+	}
+	for _, key := range []string{"GIT_AUTHOR_EMAIL", "GIT_COMMITTER_EMAIL"} {
+		t.Setenv(key, "test@example.com")
+	}
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+
+	parent, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to resolve temp dir: %v", err)
+	}
+	main := newTestRepo(t, parent, "main")
+	newTestRepo(t, parent, "child")
+	sub := filepath.Join(main, "src", "pkg")
+	if err := os.MkdirAll(sub, 0755); err != nil {
+		t.Fatalf("Failed to create subdirectory: %v", err)
+	}
+	runGit(t, main, "worktree", "add", "-q", filepath.Join(parent, "wt"))
+	runGit(t, main, "-c", "protocol.file.allow=always", "submodule", "add", "-q", "../child", "mod")
+
+	t.Run("subdirectory", func(t *testing.T) {
+		repo, err := OpenRepo(sub)
+		if err != nil {
+			t.Fatalf("OpenRepo() error = %v", err)
+		}
+		if repo.Root != main || repo.IsWorktree() || repo.IsSubmodule() {
+			t.Errorf("OpenRepo(%q) = %+v; want root %s", sub, repo, main)
+		}
+		if got := repo.ConfigPath("ignore"); got != filepath.Join(main, ".git-commit", "ignore") {
+			t.Errorf("ConfigPath(\"ignore\") = %q", got)
+		}
+	})
+
+	t.Run("worktree", func(t *testing.T) {
+		repo, err := OpenRepo(filepath.Join(parent, "wt"))
+		if err != nil {
+			t.Fatalf("OpenRepo() error = %v", err)
+		}
+		if repo.Root != filepath.Join(parent, "wt") || !repo.IsWorktree() || repo.CommonDir != filepath.Join(main, ".git") {
+			t.Errorf("Unexpected worktree repository: %+v", repo)
+		}
+	})
+
+	t.Run("submodule", func(t *testing.T) {
+		repo, err := OpenRepo(filepath.Join(main, "mod"))
+		if err != nil {
+			t.Fatalf("OpenRepo() error = %v", err)
+		}
+		if repo.Root != filepath.Join(main, "mod") || !repo.IsSubmodule() || repo.Superproject != main {
+			t.Errorf("Unexpected submodule repository: %+v", repo)
+		}
+	})
+
+	t.Run("outside", func(t *testing.T) {
+		if _, err := OpenRepo(parent); err == nil {
+			t.Error("OpenRepo() outside a repository should fail")
+		}
+	})
+}
+
+func TestNilRepoPaths(t *testing.T) {
+	var repo *Repo
+	if got := repo.ConfigPath("prompt.md"); got != filepath.Join(".git-commit", "prompt.md") {
+		t.Errorf("ConfigPath() on nil repo = %q; want a path relative to the working directory", got)
+	}
+	if _, err := repo.Rel("main.go"); err == nil {
+		t.Error("Rel() on nil repo should fail")
+	}
+}
@@ -4,50 +4,98 @@ import (
 	"fmt"
 	"os"
 	"strings"
+
+	"git-commit/internal/git"
+	"git-commit/internal/prompt"
 )
 
-// ShowHelp displays the help message for git-commit
-func ShowHelp() {
+// ShowHelp displays the help message for git-commit, listing the custom prompts of repo
+func ShowHelp(repo *git.Repo) {
 	fmt.Println("git-commit - AI-powered git commit message generator")
 	fmt.Println()
 	fmt.Println("Usage:")
-	fmt.Println("  git-commit [prompt-name]  Generate AI prompt with git diff and copy to clipboard")
-	fmt.Println("  git-commit -h             Show this help message")
-	fmt.Println("  git-commit -v             Enable verbose output")
-	fmt.Println("  git-commit -generate-prompt  Generate prompt for current changes")
+	fmt.Println("  git-commit [flags] [command] [args]")
+	fmt.Println()
+	fmt.Println("Commands:")
+	fmt.Println("  prompt [prompt-name]    Generate AI prompt with git diff and copy to clipboard (default)")
+	fmt.Println("  generate [prompt-name]  Generate the commit message with a model provider")
+	fmt.Println("  review [prompt-name]    Review, edit or regenerate the generated message, then commit it")
+	fmt.Println("  branch <name>           Validate a branch name, -checkout creates and switches to it")
+	fmt.Println("  hooks <action>          Install, uninstall or show the status of the git hooks")
+	fmt.Println("  lint [file|-]           Check a commit message (default .git/COMMIT_EDITMSG) against the rules")
+	fmt.Println("  check-ignore <path>...  Show which ignore rule excludes each path, -n also lists unmatched paths")
+	fmt.Println("  list                    List custom prompts with their descriptions, -l adds details, -q names only")
+	fmt.Println("  show [prompt-name]      Print a custom prompt (or the default prompt) without processing it")
+	fmt.Println("  init                    Create the .git-commit configuration folder")
+	fmt.Println("  doctor                  Check the environment git-commit depends on")
+	fmt.Println()
+	fmt.Println("Flags:")
+	fmt.Println("  -h                      Show this help message")
+	fmt.Println("  -v                      Enable verbose output")
+	fmt.Println("  -generate-prompt        Print the prompt instead of copying it to the clipboard")
+	fmt.Println("  -output <sink>          Where prompt and generate deliver the text: clipboard, stdout,")
+	fmt.Println("                          file:<path>, editor, commit-template or json")
+	fmt.Println("  -commit                 Commit the message generated by generate; review and -commit pass")
+	fmt.Println("                          -amend, -signoff, -sign, -sign-key, -no-sign, -author and -no-verify to git")
+	fmt.Println("  -diff <source>          Changes to describe (prompt, generate): staged (default), unstaged,")
+	fmt.Println("                          <from>..<to>, commit:<rev>, stash[:<n>] or - for a patch on stdin")
 	fmt.Println()
 	fmt.Println("Examples:")
 	fmt.Println("  git-commit              # Generate prompt and copy to clipboard")
 	fmt.Println("  git-commit mark         # Use custom prompt from custom-instructions/mark.md")
 	fmt.Println("  git-commit -v           # Run with verbose logging")
-	fmt.Println("  git-commit -generate-prompt  # Generate prompt without copying to clipboard")
+	fmt.Println("  git-commit prompt -print > prompt.txt  # Write the prompt to a file")
+	fmt.Println("  git-commit generate -output json       # Print the answer with its branch and commit as JSON")
+	fmt.Println("  git-commit generate -commit -signoff   # Commit the staged changes with the generated message")
+	fmt.Println("  git-commit review -checkout            # Review the message, switch to its branch and commit")
+	fmt.Println("  git-commit generate -provider ollama   # Generate the message with a local model")
+	fmt.Println("  git-commit prompt -diff commit:HEAD    # Describe the last commit, e.g. to reword it")
+	fmt.Println("  git-commit prompt -diff main..HEAD     # Describe every change of a branch")
+	fmt.Println()
+	fmt.Println("Exit codes:")
+	fmt.Println("  0  success")
+	fmt.Println("  1  runtime error (not a repository, git failure, ...)")
+	fmt.Println("  2  invalid usage or unknown prompt name")
+	fmt.Println("  3  commit message or branch name failed validation")
 	fmt.Println()
 	fmt.Println("Configuration:")
-	fmt.Println("  Create .git-commit/ignore file to specify patterns to ignore")
+	fmt.Println("  Create .git-commit/config.yaml to set the default prompt, output, clipboard, provider,")
+	fmt.Println("    diff limits and lint rules; ~/.config/git-commit/config.yaml holds user-wide defaults")
+	fmt.Println("  Create .git-commit/ignore file to specify patterns to ignore (gitignore syntax)")
+	fmt.Println("    Patterns are layered: ~/.config/git-commit/ignore, then .git-commit/ignore, then")
+	fmt.Println("    .git-commit-ignore files in any directory, which only apply to that directory")
 	fmt.Println("  Create .git-commit/prompt.md file for default custom AI prompt")
+	fmt.Println("  Create .git-commit/lint file with \"<rule>: <off|warning|error> [value]\" lines to tune lint rules")
 	fmt.Println("  Create .git-commit/custom-instructions/ folder with .md files for custom prompts")
 	fmt.Println("    Example: .git-commit/custom-instructions/mark.md")
 	fmt.Println("    Usage: git-commit mark")
+	fmt.Println("  Set GIT_COMMIT_PROVIDER (openai, anthropic, ollama), GIT_COMMIT_MODEL and GIT_COMMIT_BASE_URL")
+	fmt.Println("    to choose the model used by generate; API keys come from OPENAI_API_KEY or ANTHROPIC_API_KEY")
 	fmt.Println()
 	
 	// Show available custom prompts
-	customPrompts := GetAvailableCustomPrompts()
+	customPrompts := GetAvailableCustomPrompts(repo)
 	if len(customPrompts) > 0 {
 		fmt.Println("Available custom prompts:")
 		for _, promptName := range customPrompts {
-			fmt.Printf("  git-commit %s\n", promptName)
+			meta, _ := prompt.Metadata(repo, promptName)
+			if meta.Description == "" {
+				fmt.Printf("  git-commit %s\n", promptName)
+				continue
+			}
+			fmt.Printf("  git-commit %-20s %s\n", promptName, meta.Description)
 		}
 	} else {
 		fmt.Println("No custom prompts found. Create .git-commit/custom-instructions/ folder with .md files.")
 	}
 }
 
-// GetAvailableCustomPrompts scans the custom-instructions directory and returns available prompt names
-func GetAvailableCustomPrompts() []string {
+// GetAvailableCustomPrompts scans the custom-instructions directory of the repository and returns available prompt names
+func GetAvailableCustomPrompts(repo *git.Repo) []string {
 	var prompts []string
 	
 	// Check if custom-instructions directory exists
-	customInstructionsPath := ".git-commit/custom-instructions"
+	customInstructionsPath := repo.ConfigPath("custom-instructions")
 	if _, err := os.Stat(customInstructionsPath); os.IsNotExist(err) {
 		return prompts
 	}
@@ -0,0 +1,170 @@
+package hooks
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"git-commit/internal/git"
+)
+
+// Marker identifies hook scripts written by git-commit
+const Marker = "# installed by git-commit"
+
+// chainSuffix is appended to pre-existing hooks that git-commit runs before its own logic
+const chainSuffix = ".local"
+
+// Names lists the hooks managed by git-commit
+var Names = []string{"prepare-commit-msg", "commit-msg"}
+
+// scriptTemplate runs a chained pre-existing hook first, then hands over to git-commit.
+// The binary is referenced by absolute path because git puts its own git-commit
+// command first in PATH while running hooks. A missing binary never blocks a commit.
+const scriptTemplate = `#!/bin/sh
+%s
+hook_dir=$(dirname "$0")
+if [ -x "$hook_dir/%[2]s%[3]s" ]; then
+	"$hook_dir/%[2]s%[3]s" "$@" || exit $?
+fi
+git_commit_bin=${GIT_COMMIT_BIN:-'%[4]s'}
+[ -x "$git_commit_bin" ] || exit 0
+exec "$git_commit_bin" hook %[2]s "$@"
+`
+
+// Status describes a hook in the hooks directory
+type Status struct {
+	Name      string
+	Path      string
+	Installed bool // the hook is a git-commit script
+	Chained   bool // a pre-existing hook is run before git-commit
+	Foreign   bool // another tool's hook occupies the name
+}
+
+// Dir returns the directory git runs hooks from for repo, honouring core.hooksPath and husky
+func Dir(repo *git.Repo) (string, error) {
+	hooksPath, err := git.ConfigValue("core.hooksPath")
+	if err != nil {
+		return "", err
+	}
+
+	if hooksPath == "" {
+		dir, err := git.GitPath("hooks")
+		if err != nil {
+			return "", err
+		}
+		return filepath.Abs(dir)
+	}
+
+	if !filepath.IsAbs(hooksPath) {
+		hooksPath = repo.Path(hooksPath)
+	}
+
+	// husky v9 points core.hooksPath at .husky/_, whose generated wrappers run the user scripts in .husky
+	if filepath.Base(hooksPath) == "_" && filepath.Base(filepath.Dir(hooksPath)) == ".husky" {
+		return filepath.Dir(hooksPath), nil
+	}
+	return hooksPath, nil
+}
+
+// Script returns the content of the git-commit script for a hook that runs binary
+func Script(name, binary string) string {
+	return fmt.Sprintf(scriptTemplate, Marker, name, chainSuffix, strings.ReplaceAll(binary, "'", `'\''`))
+}
+
+// Install writes hooks into dir that run the git-commit binary at the given absolute path.
+// Existing hooks from other tools are kept and chained by renaming them with a ".local"
+// suffix, unless force is set.
+func Install(dir, binary string, force bool) ([]Status, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create hooks directory %s: %v", dir, err)
+	}
+
+	for _, name := range Names {
+		path := filepath.Join(dir, name)
+		content, err := os.ReadFile(path)
+		switch {
+		case os.IsNotExist(err):
+		case err != nil:
+			return nil, fmt.Errorf("failed to read hook %s: %v", path, err)
+		case isOurs(content):
+		case force:
+			// Overwrite without chaining
+		default:
+			chained := path + chainSuffix
+			if _, err := os.Stat(chained); err == nil {
+				return nil, fmt.Errorf("cannot chain existing hook %s: %s already exists", path, chained)
+			}
+			if err := os.Rename(path, chained); err != nil {
+				return nil, fmt.Errorf("failed to move existing hook %s: %v", path, err)
+			}
+		}
+
+		if err := os.WriteFile(path, []byte(Script(name, binary)), 0755); err != nil {
+			return nil, fmt.Errorf("failed to write hook %s: %v", path, err)
+		}
+		// WriteFile keeps the mode of an existing file
+		if err := os.Chmod(path, 0755); err != nil {
+			return nil, fmt.Errorf("failed to make hook %s executable: %v", path, err)
+		}
+	}
+
+	return List(dir)
+}
+
+// Uninstall removes the git-commit hooks from dir and restores chained hooks
+func Uninstall(dir string) ([]Status, error) {
+	for _, name := range Names {
+		path := filepath.Join(dir, name)
+		content, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read hook %s: %v", path, err)
+		}
+		if !isOurs(content) {
+			continue
+		}
+
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("failed to remove hook %s: %v", path, err)
+		}
+		chained := path + chainSuffix
+		if _, err := os.Stat(chained); err == nil {
+			if err := os.Rename(chained, path); err != nil {
+				return nil, fmt.Errorf("failed to restore hook %s: %v", chained, err)
+			}
+		}
+	}
+
+	return List(dir)
+}
+
+// List reports the state of each managed hook in dir
+func List(dir string) ([]Status, error) {
+	var result []Status
+	for _, name := range Names {
+		status := Status{Name: name, Path: filepath.Join(dir, name)}
+
+		content, err := os.ReadFile(status.Path)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to read hook %s: %v", status.Path, err)
+		}
+		if err == nil {
+			status.Installed = isOurs(content)
+			status.Foreign = !status.Installed
+		}
+		if _, err := os.Stat(status.Path + chainSuffix); err == nil {
+			status.Chained = true
+		}
+
+		result = append(result, status)
+	}
+	return result, nil
+}
+
+// isOurs reports whether a hook script was written by git-commit
+func isOurs(content []byte) bool {
+	return strings.Contains(string(content), Marker)
+}
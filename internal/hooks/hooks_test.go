@@ -0,0 +1,98 @@
+package hooks
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInstallAndUninstall(t *testing.T) {
+	dir := t.TempDir()
+
+	statuses, err := Install(dir, "/usr/local/bin/git-commit", false)
+	if err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+	for _, status := range statuses {
+		if !status.Installed || status.Chained || status.Foreign {
+			t.Errorf("Unexpected status after install: %+v", status)
+		}
+		info, err := os.Stat(status.Path)
+		if err != nil {
+			t.Fatalf("Hook %s not written: %v", status.Name, err)
+		}
+		if info.Mode()&0100 == 0 {
+			t.Errorf("Hook %s is not executable", status.Name)
+		}
+	}
+
+	// Installing twice keeps a single script and does not chain it with itself
+	if _, err := Install(dir, "/usr/local/bin/git-commit", false); err != nil {
+		t.Fatalf("Second Install() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "commit-msg"+chainSuffix)); !os.IsNotExist(err) {
+		t.Error("Reinstalling should not chain git-commit with itself")
+	}
+
+	statuses, err = Uninstall(dir)
+	if err != nil {
+		t.Fatalf("Uninstall() error = %v", err)
+	}
+	for _, status := range statuses {
+		if status.Installed || status.Foreign {
+			t.Errorf("Unexpected status after uninstall: %+v", status)
+		}
+	}
+}
+
+func TestInstallChainsExistingHook(t *testing.T) {
+	dir := t.TempDir()
+	existing := "#!/bin/sh\necho existing\n"
+	hookPath := filepath.Join(dir, "commit-msg")
+	if err := os.WriteFile(hookPath, []byte(existing), 0755); err != nil {
+		t.Fatalf("Failed to write existing hook: %v", err)
+	}
+
+	statuses, err := Install(dir, "/usr/local/bin/git-commit", false)
+	if err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+	for _, status := range statuses {
+		if status.Name == "commit-msg" && !status.Chained {
+			t.Errorf("Expected existing commit-msg hook to be chained: %+v", status)
+		}
+	}
+
+	script, _ := os.ReadFile(hookPath)
+	if !strings.Contains(string(script), "commit-msg"+chainSuffix) {
+		t.Errorf("Hook script does not run the chained hook:\n%s", script)
+	}
+
+	if _, err := Uninstall(dir); err != nil {
+		t.Fatalf("Uninstall() error = %v", err)
+	}
+	restored, err := os.ReadFile(hookPath)
+	if err != nil || string(restored) != existing {
+		t.Errorf("Expected existing hook to be restored, got %q (%v)", restored, err)
+	}
+}
+
+func TestUninstallKeepsForeignHook(t *testing.T) {
+	dir := t.TempDir()
+	hookPath := filepath.Join(dir, "prepare-commit-msg")
+	if err := os.WriteFile(hookPath, []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatalf("Failed to write foreign hook: %v", err)
+	}
+
+	statuses, err := Uninstall(dir)
+	if err != nil {
+		t.Fatalf("Uninstall() error = %v", err)
+	}
+	if _, err := os.Stat(hookPath); err != nil {
+		t.Errorf("Foreign hook should not be removed: %v", err)
+	}
+	if !statuses[0].Foreign {
+		t.Errorf("Expected foreign status, got %+v", statuses[0])
+	}
+}
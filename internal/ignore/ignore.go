@@ -0,0 +1,239 @@
+package ignore
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Pattern is a single compiled gitignore pattern
+type Pattern struct {
+	Raw      string // the pattern as written in the ignore file
+	Negate   bool   // pattern starts with "!" and re-includes matching paths
+	DirOnly  bool   // pattern ends with "/" and only matches directories
+	Anchored bool   // pattern contains a non-trailing "/" and is matched from the root
+	Base     string // slash-separated directory the pattern is scoped to, "" for the repository root
+	Source   string // file the pattern was read from, empty for patterns built from plain lines
+	Line     int    // 1-based line in Source
+	regex    *regexp.Regexp
+}
+
+// ParsePattern compiles a line of an ignore file using gitignore semantics.
+// It returns a nil pattern for blank lines and comments, and an error for patterns
+// that cannot be compiled, such as the reversed bracket range "[z-a]".
+func ParsePattern(line string) (*Pattern, error) {
+	line = strings.TrimSuffix(line, "\r")
+	line = trimTrailingSpaces(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return nil, nil
+	}
+
+	p := &Pattern{Raw: line}
+	if strings.HasPrefix(line, "!") {
+		p.Negate = true
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") && !strings.HasSuffix(line, "\\/") {
+		p.DirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return nil, nil
+	}
+
+	// A slash at the beginning or in the middle anchors the pattern to the ignore file's directory
+	if strings.Contains(line, "/") {
+		p.Anchored = true
+		line = strings.TrimPrefix(line, "/")
+	}
+
+	expr := "^"
+	if !p.Anchored {
+		expr += "(?:.*/)?"
+	}
+	expr += translate(line) + "$"
+	regex, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern '%s': %v", p.Raw, err)
+	}
+	p.regex = regex
+
+	return p, nil
+}
+
+// Match reports whether the slash-separated path matches the pattern, ignoring negation.
+// Paths outside the pattern's base directory never match.
+func (p *Pattern) Match(path string, isDir bool) bool {
+	if p.DirOnly && !isDir {
+		return false
+	}
+	if p.Base != "" {
+		if !strings.HasPrefix(path, p.Base+"/") {
+			return false
+		}
+		path = path[len(p.Base)+1:]
+	}
+	return p.regex.MatchString(path)
+}
+
+// Matcher evaluates an ordered list of patterns where the last matching pattern wins
+type Matcher struct {
+	patterns []*Pattern
+}
+
+// NewMatcher compiles the lines of an ignore file, skipping blank lines and comments.
+// It fails on the first pattern that cannot be compiled.
+func NewMatcher(lines []string) (*Matcher, error) {
+	m := &Matcher{}
+	for _, line := range lines {
+		p, err := ParsePattern(line)
+		if err != nil {
+			return nil, err
+		}
+		if p != nil {
+			m.patterns = append(m.patterns, p)
+		}
+	}
+	return m, nil
+}
+
+// Add appends patterns, which take precedence over the ones already in the matcher
+func (m *Matcher) Add(patterns ...*Pattern) {
+	m.patterns = append(m.patterns, patterns...)
+}
+
+// Patterns returns the compiled patterns in evaluation order
+func (m *Matcher) Patterns() []*Pattern {
+	return m.patterns
+}
+
+// Match reports whether path is ignored. Like git, a file inside an ignored directory
+// stays ignored even if a later pattern re-includes the file itself.
+func (m *Matcher) Match(path string, isDir bool) bool {
+	p := m.Explain(path, isDir)
+	return p != nil && !p.Negate
+}
+
+// Explain returns the pattern that decides whether path is ignored, or nil if no pattern
+// matches. The pattern is a negation when the path was re-included.
+func (m *Matcher) Explain(path string, isDir bool) *Pattern {
+	path = strings.Trim(strings.ReplaceAll(path, "\\", "/"), "/")
+	if path == "" {
+		return nil
+	}
+
+	parts := strings.Split(path, "/")
+	for i := 1; i < len(parts); i++ {
+		if p := m.matchOne(strings.Join(parts[:i], "/"), true); p != nil && !p.Negate {
+			return p
+		}
+	}
+	return m.matchOne(path, isDir)
+}
+
+// matchOne returns the last pattern matching a single path without looking at its parents
+func (m *Matcher) matchOne(path string, isDir bool) *Pattern {
+	var last *Pattern
+	for _, p := range m.patterns {
+		if p.Match(path, isDir) {
+			last = p
+		}
+	}
+	return last
+}
+
+// trimTrailingSpaces removes unescaped trailing spaces
+func trimTrailingSpaces(line string) string {
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, "\\ ") {
+		line = line[:len(line)-1]
+	}
+	return line
+}
+
+// translate converts a gitignore glob into a regular expression
+func translate(glob string) string {
+	var b strings.Builder
+	runes := []rune(glob)
+
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+		switch c {
+		case '*':
+			// Count consecutive asterisks
+			j := i
+			for j < len(runes) && runes[j] == '*' {
+				j++
+			}
+			stars := j - i
+			atStart := i == 0 || runes[i-1] == '/'
+			atEnd := j == len(runes) || runes[j] == '/'
+
+			switch {
+			case stars >= 2 && atStart && j < len(runes) && runes[j] == '/':
+				// "**/" matches zero or more directories
+				b.WriteString("(?:.*/)?")
+				j++ // consume the slash
+			case stars >= 2 && atStart && atEnd:
+				// trailing "/**" matches everything inside
+				b.WriteString(".*")
+			default:
+				b.WriteString("[^/]*")
+			}
+			i = j - 1
+		case '?':
+			b.WriteString("[^/]")
+		case '[':
+			class, next, ok := translateClass(runes, i)
+			if !ok {
+				b.WriteString(`\[`)
+				continue
+			}
+			b.WriteString(class)
+			i = next
+		case '\\':
+			if i+1 < len(runes) {
+				i++
+				b.WriteString(regexp.QuoteMeta(string(runes[i])))
+			} else {
+				b.WriteString(`\\`)
+			}
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	return b.String()
+}
+
+// translateClass converts a bracket expression starting at runes[start] and returns
+// the regexp class, the index of the closing bracket and whether the class was closed
+func translateClass(runes []rune, start int) (string, int, bool) {
+	var b strings.Builder
+	b.WriteString("[")
+	i := start + 1
+	if i < len(runes) && (runes[i] == '!' || runes[i] == '^') {
+		b.WriteString("^/")
+		i++
+	}
+	// A "]" right after the opening bracket is a literal
+	first := true
+	for ; i < len(runes); i++ {
+		c := runes[i]
+		switch {
+		case c == ']' && !first:
+			b.WriteString("]")
+			return b.String(), i, true
+		case c == '\\' && i+1 < len(runes):
+			i++
+			b.WriteString(regexp.QuoteMeta(string(runes[i])))
+		case c == '-':
+			b.WriteString("-")
+		case c == '[' || c == ']' || c == '^':
+			b.WriteString(`\` + string(c))
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+		first = false
+	}
+	return "", start, false
+}
@@ -0,0 +1,83 @@
+package ignore
+
+import (
+	"testing"
+)
+
+func TestMatcher(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		path     string
+		isDir    bool
+		expected bool
+	}{
+		{"Extension anywhere", []string{"*.log"}, "logs/app.log", false, true},
+		{"Extension no match", []string{"*.log"}, "app.txt", false, false},
+		{"Question mark", []string{"foo?.txt"}, "foo1.txt", false, true},
+		{"Question mark needs a character", []string{"foo?.txt"}, "foo.txt", false, false},
+		{"Character class", []string{"file[0-9].txt"}, "file7.txt", false, true},
+		{"Negated character class", []string{"file[!0-9].txt"}, "file7.txt", false, false},
+		{"Name matches directory at any depth", []string{"node_modules"}, "web/node_modules/react/index.js", false, true},
+		{"Directory only pattern matches contents", []string{"build/"}, "build/out.bin", false, true},
+		{"Directory only pattern skips files", []string{"build/"}, "build", false, false},
+		{"Anchored pattern", []string{"/build"}, "build/out.bin", false, true},
+		{"Anchored pattern not nested", []string{"/build"}, "src/build/out.bin", false, false},
+		{"Middle slash anchors", []string{"docs/*.md"}, "docs/api.md", false, true},
+		{"Middle slash anchored not nested", []string{"docs/*.md"}, "web/docs/api.md", false, false},
+		{"Star does not cross directories", []string{"docs/*.md"}, "docs/v1/api.md", false, false},
+		{"Leading double star", []string{"**/fixtures/**"}, "pkg/a/fixtures/data.json", false, true},
+		{"Middle double star", []string{"a/**/b"}, "a/x/y/b", false, true},
+		{"Middle double star zero dirs", []string{"a/**/b"}, "a/b", false, true},
+		{"Trailing double star", []string{"vendor/**"}, "vendor/lib/x.go", false, true},
+		{"Negation re-includes", []string{"*.log", "!keep.log"}, "keep.log", false, false},
+		{"Last match wins", []string{"!keep.log", "*.log"}, "keep.log", false, true},
+		{"Parent directory cannot be re-included", []string{"logs/", "!logs/keep.log"}, "logs/keep.log", false, true},
+		{"Escaped hash", []string{`\#notes`}, "#notes", false, true},
+		{"Escaped bang", []string{`\!important`}, "!important", false, true},
+		{"Escaped star", []string{`star\*`}, "star*", false, true},
+		{"Escaped star no wildcard", []string{`star\*`}, "stars", false, false},
+		{"Trailing spaces ignored", []string{"*.tmp   "}, "a.tmp", false, true},
+		{"Escaped trailing space kept", []string{`name\ `}, "name ", false, true},
+		{"Comment is not a pattern", []string{"# *.go"}, "main.go", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := NewMatcher(tt.patterns)
+			if err != nil {
+				t.Fatalf("NewMatcher(%q) error = %v", tt.patterns, err)
+			}
+			if result := m.Match(tt.path, tt.isDir); result != tt.expected {
+				t.Errorf("Match(%q) with %q = %v; want %v", tt.path, tt.patterns, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParsePatternFlags(t *testing.T) {
+	p, err := ParsePattern("!/build/")
+	if err != nil || p == nil {
+		t.Fatalf("ParsePattern() = %v, %v; want a pattern", p, err)
+	}
+	if !p.Negate || !p.DirOnly || !p.Anchored {
+		t.Errorf("Unexpected flags: %+v", p)
+	}
+
+	for _, line := range []string{"", "   ", "# comment", "!"} {
+		if p, err := ParsePattern(line); p != nil || err != nil {
+			t.Errorf("ParsePattern(%q) = %v, %v; want no pattern", line, p, err)
+		}
+	}
+}
+
+func TestParsePatternInvalid(t *testing.T) {
+	for _, line := range []string{"[z-a].go", "src/[9-0]*"} {
+		if _, err := ParsePattern(line); err == nil {
+			t.Errorf("ParsePattern(%q) should fail", line)
+		}
+	}
+	if _, err := NewMatcher([]string{"*.log", "[z-a].go"}); err == nil {
+		t.Error("NewMatcher() with an invalid bracket range should fail")
+	}
+}
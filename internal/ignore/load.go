@@ -0,0 +1,129 @@
+package ignore
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"git-commit/internal/config"
+)
+
+// RepoFile is the repository-wide ignore file, relative to the repository root
+const RepoFile = ".git-commit/ignore"
+
+// DirFile is the name of per-directory ignore files, which only apply to their own subtree
+const DirFile = ".git-commit-ignore"
+
+// GlobalPath returns the user-wide ignore file, $XDG_CONFIG_HOME/git-commit/ignore
+// or ~/.config/git-commit/ignore, or "" if the home directory is unknown
+func GlobalPath() string {
+	dir := config.UserDir()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "ignore")
+}
+
+// ReadFile compiles the patterns of an ignore file scoped to base, a slash-separated
+// directory relative to the repository root. A missing file yields no patterns.
+func ReadFile(filename, source, base string) ([]*Pattern, error) {
+	file, err := os.Open(filename)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file %s: %v", source, err)
+	}
+	defer file.Close()
+
+	var patterns []*Pattern
+	scanner := bufio.NewScanner(file)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		p, err := ParsePattern(scanner.Text())
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", source, lineNumber, err)
+		}
+		if p != nil {
+			p.Base, p.Source, p.Line = base, source, lineNumber
+			patterns = append(patterns, p)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading file %s: %v", source, err)
+	}
+
+	return patterns, nil
+}
+
+// Load builds the matcher for the repository at root from the global ignore file, the
+// repository ignore file and the per-directory ignore files of the directories containing
+// paths. Later layers take precedence: the global file, then the repository file, then
+// per-directory files from the root down.
+func Load(root string, paths []string) (*Matcher, error) {
+	m := &Matcher{}
+
+	if global := GlobalPath(); global != "" {
+		patterns, err := ReadFile(global, global, "")
+		if err != nil {
+			return nil, err
+		}
+		m.Add(patterns...)
+	}
+
+	patterns, err := ReadFile(filepath.Join(root, filepath.FromSlash(RepoFile)), RepoFile, "")
+	if err != nil {
+		return nil, err
+	}
+	m.Add(patterns...)
+
+	for _, dir := range parentDirs(paths) {
+		source := path.Join(dir, DirFile)
+		base := dir
+		if dir == "." {
+			base = ""
+		}
+		patterns, err := ReadFile(filepath.Join(root, filepath.FromSlash(source)), source, base)
+		if err != nil {
+			return nil, err
+		}
+		m.Add(patterns...)
+	}
+
+	return m, nil
+}
+
+// parentDirs returns every directory containing one of the slash-separated paths,
+// including the root as ".", ordered from the shallowest to the deepest
+func parentDirs(paths []string) []string {
+	seen := map[string]bool{".": true}
+	dirs := []string{"."}
+	for _, p := range paths {
+		for dir := path.Dir(strings.Trim(p, "/")); dir != "." && dir != "/" && !seen[dir]; dir = path.Dir(dir) {
+			seen[dir] = true
+			dirs = append(dirs, dir)
+		}
+	}
+
+	sort.SliceStable(dirs, func(i, j int) bool {
+		di, dj := depth(dirs[i]), depth(dirs[j])
+		if di != dj {
+			return di < dj
+		}
+		return dirs[i] < dirs[j]
+	})
+	return dirs
+}
+
+// depth returns the number of path segments in dir, 0 for the root
+func depth(dir string) int {
+	if dir == "." {
+		return 0
+	}
+	return strings.Count(dir, "/") + 1
+}
@@ -0,0 +1,123 @@
+package ignore
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
+
+func TestLoadLayers(t *testing.T) {
+	config := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", config)
+	writeFile(t, filepath.Join(config, "git-commit", "ignore"), "*.log\n*.snap\n")
+
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, ".git-commit", "ignore"), "# repository rules\n!keep.log\n/dist/\n")
+	writeFile(t, filepath.Join(root, "web", DirFile), "*.svg\n/generated.ts\n!*.snap\n")
+	writeFile(t, filepath.Join(root, "web", "icons", DirFile), "!logo.svg\n")
+
+	paths := []string{
+		"app.log", "keep.log", "dist/app.js", "src/dist/app.js",
+		"web/a.svg", "web/icons/logo.svg", "web/icons/other.svg", "api/a.svg",
+		"web/generated.ts", "web/src/generated.ts", "web/ui.snap", "api/ui.snap",
+	}
+	m, err := Load(root, paths)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	tests := []struct {
+		path     string
+		expected bool
+	}{
+		{"app.log", true},
+		{"keep.log", false},             // repository file overrides the global file
+		{"dist/app.js", true},           // anchored to the repository root
+		{"src/dist/app.js", false},      // anchored pattern does not match nested directories
+		{"web/a.svg", true},             // per-directory file applies to its subtree
+		{"web/icons/logo.svg", false},   // deeper files override shallower ones
+		{"web/icons/other.svg", true},   // inherited from web/
+		{"api/a.svg", false},            // per-directory file does not leak into siblings
+		{"web/generated.ts", true},      // anchored to the directory of the ignore file
+		{"web/src/generated.ts", false}, // ...and not to its subdirectories
+		{"web/ui.snap", false},          // per-directory negation overrides the global file
+		{"api/ui.snap", true},
+	}
+	for _, tt := range tests {
+		if result := m.Match(tt.path, false); result != tt.expected {
+			t.Errorf("Match(%q) = %v; want %v", tt.path, result, tt.expected)
+		}
+	}
+}
+
+func TestLoadInvalidPattern(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, ".git-commit", "ignore"), "*.log\n[z-a].go\n")
+
+	_, err := Load(root, []string{"main.go"})
+	if err == nil || !strings.HasPrefix(err.Error(), RepoFile+":2: invalid pattern '[z-a].go'") {
+		t.Errorf("Load() error = %v; want the file and line of the invalid pattern", err)
+	}
+}
+
+func TestExplain(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, ".git-commit", "ignore"), "*.log\n\nlogs/\n!keep.log\n")
+
+	m, err := Load(root, nil)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	tests := []struct {
+		path   string
+		raw    string
+		line   int
+		negate bool
+	}{
+		{"debug.log", "*.log", 1, false},
+		{"keep.log", "!keep.log", 4, true},
+		{"logs/keep.log", "logs/", 3, false}, // the excluded parent directory decides
+	}
+	for _, tt := range tests {
+		p := m.Explain(tt.path, false)
+		if p == nil {
+			t.Errorf("Explain(%q) = nil; want %q", tt.path, tt.raw)
+			continue
+		}
+		if p.Raw != tt.raw || p.Line != tt.line || p.Negate != tt.negate || p.Source != RepoFile {
+			t.Errorf("Explain(%q) = %s:%d:%s; want %s:%d:%s", tt.path, p.Source, p.Line, p.Raw, RepoFile, tt.line, tt.raw)
+		}
+	}
+
+	if p := m.Explain("main.go", false); p != nil {
+		t.Errorf("Explain(%q) = %q; want nil", "main.go", p.Raw)
+	}
+}
+
+func TestParentDirs(t *testing.T) {
+	dirs := parentDirs([]string{"b/c/d.go", "a/x.go", "b/e.go", "top.go"})
+	expected := []string{".", "a", "b", "b/c"}
+	if len(dirs) != len(expected) {
+		t.Fatalf("parentDirs() = %q; want %q", dirs, expected)
+	}
+	for i := range dirs {
+		if dirs[i] != expected[i] {
+			t.Errorf("parentDirs() = %q; want %q", dirs, expected)
+			break
+		}
+	}
+}
@@ -0,0 +1,266 @@
+package lint
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"git-commit/internal/parser"
+)
+
+// Severity controls how a rule violation is reported
+type Severity string
+
+const (
+	SeverityOff     Severity = "off"
+	SeverityWarning Severity = "warning"
+	SeverityError   Severity = "error"
+)
+
+// ConfigPath is the repository file holding per-rule severity overrides
+const ConfigPath = ".git-commit/lint"
+
+// RuleConfig is the configured severity and optional value of a rule
+type RuleConfig struct {
+	Severity Severity
+	Value    string // rule argument, e.g. a maximum length or a comma-separated list
+}
+
+// Config maps rule IDs to their configuration
+type Config map[string]RuleConfig
+
+// Issue is a single rule violation
+type Issue struct {
+	Rule     string
+	Severity Severity
+	Line     int // 1-based line in the linted message, 0 for message-wide issues
+	Message  string
+}
+
+func (i Issue) String() string {
+	if i.Line > 0 {
+		return fmt.Sprintf("%d: %s [%s] %s", i.Line, i.Severity, i.Rule, i.Message)
+	}
+	return fmt.Sprintf("%s [%s] %s", i.Severity, i.Rule, i.Message)
+}
+
+// DefaultConfig returns the rule configuration matching the rules declared by the default prompt
+func DefaultConfig() Config {
+	cfg := Config{}
+	for _, r := range rules {
+		cfg[r.id] = RuleConfig{Severity: r.severity, Value: r.value}
+	}
+	return cfg
+}
+
+// Rules returns the IDs and descriptions of all rules, sorted by ID
+func Rules() [][2]string {
+	var result [][2]string
+	for _, r := range rules {
+		result = append(result, [2]string{r.id, r.description})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i][0] < result[j][0] })
+	return result
+}
+
+// LoadConfig reads rule overrides from path and applies them on top of the defaults.
+// Each line has the form "<rule>: <off|warning|error> [value]"; a missing file yields the defaults.
+func LoadConfig(path string) (Config, error) {
+	cfg := DefaultConfig()
+
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return cfg, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file %s: %v", path, err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		// Skip empty lines and comments
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		id, setting, found := strings.Cut(line, ":")
+		if !found {
+			return nil, fmt.Errorf("%s:%d: expected \"<rule>: <severity> [value]\"", path, lineNumber)
+		}
+		if err := cfg.Set(strings.TrimSpace(id), setting); err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", path, lineNumber, err)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading file %s: %v", path, err)
+	}
+
+	return cfg, nil
+}
+
+// Set applies a "<off|warning|error> [value]" setting to the rule with the given ID
+func (c Config) Set(id, setting string) error {
+	ruleCfg, known := c[id]
+	if !known {
+		return fmt.Errorf("unknown rule '%s'", id)
+	}
+
+	fields := strings.Fields(setting)
+	if len(fields) == 0 {
+		return fmt.Errorf("missing severity for rule '%s'", id)
+	}
+	severity, err := ParseSeverity(fields[0])
+	if err != nil {
+		return err
+	}
+	ruleCfg.Severity = severity
+	if len(fields) > 1 {
+		ruleCfg.Value = strings.Join(fields[1:], " ")
+		if err := findRule(id).validate(ruleCfg.Value); err != nil {
+			return fmt.Errorf("invalid value for rule '%s': %v", id, err)
+		}
+	}
+	c[id] = ruleCfg
+	return nil
+}
+
+// ParseSeverity converts a severity name into a Severity
+func ParseSeverity(name string) (Severity, error) {
+	switch Severity(strings.ToLower(name)) {
+	case SeverityOff:
+		return SeverityOff, nil
+	case SeverityWarning:
+		return SeverityWarning, nil
+	case SeverityError:
+		return SeverityError, nil
+	}
+	return "", fmt.Errorf("unknown severity '%s', expected off, warning or error", name)
+}
+
+// Lint checks a commit message against the configured rules
+func Lint(message string, cfg Config) []Issue {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+	msg := newMessage(message)
+
+	var issues []Issue
+	for _, r := range rules {
+		ruleCfg, ok := cfg[r.id]
+		if !ok {
+			ruleCfg = RuleConfig{Severity: r.severity, Value: r.value}
+		}
+		if ruleCfg.Severity == SeverityOff {
+			continue
+		}
+		// Rules that inspect the parsed message are skipped when the header is malformed
+		if r.needsCommit && msg.commit == nil {
+			continue
+		}
+		for _, f := range r.check(msg, ruleCfg.Value) {
+			issues = append(issues, Issue{Rule: r.id, Severity: ruleCfg.Severity, Line: f.line, Message: f.message})
+		}
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool { return issues[i].Line < issues[j].Line })
+	return issues
+}
+
+// generatedPrefixes start the headers git writes itself for merges, reverts and autosquash commits
+var generatedPrefixes = []string{"Merge ", "Revert ", "fixup! ", "squash! ", "amend! "}
+
+// IsGenerated reports whether a message was written by git for a merge, a revert or a
+// --fixup/--squash commit, which does not follow the Conventional Commits format
+func IsGenerated(message string) bool {
+	for _, line := range strings.Split(message, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		for _, prefix := range generatedPrefixes {
+			if strings.HasPrefix(line, prefix) {
+				return true
+			}
+		}
+		return false
+	}
+	return false
+}
+
+// HasErrors reports whether any issue has error severity
+func HasErrors(issues []Issue) bool {
+	for _, issue := range issues {
+		if issue.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// StripComments removes git comment lines and everything below a scissors line,
+// matching what git does to a message edited in COMMIT_EDITMSG
+func StripComments(message string) string {
+	var kept []string
+	for _, line := range strings.Split(strings.ReplaceAll(message, "\r\n", "\n"), "\n") {
+		if strings.HasPrefix(line, "# ------------------------ >8 ------------------------") {
+			break
+		}
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		kept = append(kept, line)
+	}
+	return strings.TrimRight(strings.Join(kept, "\n"), "\n \t") + "\n"
+}
+
+// message is the linted commit message split into lines, with its parsed form if the header is valid
+type message struct {
+	lines  []string
+	header int // index of the header line
+	commit *parser.Commit
+}
+
+func newMessage(text string) *message {
+	msg := &message{lines: strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")}
+	for i, line := range msg.lines {
+		if strings.TrimSpace(line) != "" {
+			msg.header = i
+			break
+		}
+	}
+	msg.commit, _ = parser.ParseMessage(text)
+	return msg
+}
+
+// headerLine returns the raw header line
+func (m *message) headerLine() string {
+	return strings.TrimSpace(m.lines[m.header])
+}
+
+// bodyLines returns the 1-based line numbers and content of the lines after the header
+func (m *message) bodyLines() ([]int, []string) {
+	var numbers []int
+	var lines []string
+	for i := m.header + 1; i < len(m.lines); i++ {
+		numbers = append(numbers, i+1)
+		lines = append(lines, m.lines[i])
+	}
+	return numbers, lines
+}
+
+// lineOf returns the 1-based line number of the first line after the header containing text, or 0
+func (m *message) lineOf(text string) int {
+	for i := m.header + 1; i < len(m.lines); i++ {
+		if strings.Contains(m.lines[i], text) {
+			return i + 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,189 @@
+package lint
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// ruleIDs returns the rule IDs of the issues
+func ruleIDs(issues []Issue) []string {
+	var ids []string
+	for _, issue := range issues {
+		ids = append(ids, issue.Rule)
+	}
+	return ids
+}
+
+func TestLintValidMessage(t *testing.T) {
+	message := "feat(auth): implement user login\n\n" +
+		"- Add authentication service\n" +
+		"- Create login component\n\n" +
+		"BREAKING CHANGE: sessions from v1 are invalidated\n" +
+		"Refs: #123, AUTH-7\n"
+
+	if issues := Lint(message, nil); len(issues) != 0 {
+		t.Errorf("Expected no issues, got %v", issues)
+	}
+}
+
+func TestLintRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		rule    string
+		line    int
+	}{
+		{"Empty message", "\n", "header-format", 1},
+		{"Malformed header", "Add login form", "header-format", 1},
+		{"Header too long", "feat: add a very long description that goes past the limit", "header-max-length", 1},
+		{"Unknown type", "feature: add login", "type-enum", 1},
+		{"Past tense", "fix: fixed login redirect", "header-imperative", 1},
+		{"Third person", "docs: updates readme", "header-imperative", 1},
+		{"Capitalized description", "feat: Add login", "description-case", 1},
+		{"Trailing period", "fix: resolve crash.", "header-full-stop", 1},
+		{"No blank line", "fix: resolve crash\n- Guard nil pointer", "body-leading-blank", 2},
+		{"Long body line", "fix: resolve crash\n\n- " + strings.Repeat("x", 80), "body-max-line-length", 3},
+		{"Lowercase bullet", "fix: resolve crash\n\n- guard nil pointer", "body-bullet-case", 3},
+		{"Bullet period", "fix: resolve crash\n\n- Guard nil pointer.", "body-bullet-full-stop", 3},
+		{"Lowercase breaking change", "feat: drop v1\n\nbreaking change: v1 removed", "breaking-change-format", 3},
+		{"Bad refs token", "fix: resolve crash\n\nrefs: #12", "refs-format", 3},
+		{"Bad refs value", "fix: resolve crash\n\nRefs: ticket 12", "refs-format", 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues := Lint(tt.message, nil)
+			for _, issue := range issues {
+				if issue.Rule == tt.rule {
+					if issue.Line != tt.line {
+						t.Errorf("Issue %q reported on line %d; want %d", tt.rule, issue.Line, tt.line)
+					}
+					return
+				}
+			}
+			t.Errorf("Expected %q issue, got %v", tt.rule, ruleIDs(issues))
+		})
+	}
+}
+
+func TestLintAcronymDescription(t *testing.T) {
+	for _, issue := range Lint("docs: API reference for login", nil) {
+		if issue.Rule == "description-case" {
+			t.Errorf("Acronyms should not trigger description-case: %v", issue)
+		}
+	}
+}
+
+func TestImperativeOf(t *testing.T) {
+	tests := []struct {
+		word       string
+		suggestion string
+		ok         bool
+	}{
+		{"add", "add", true},
+		{"embed", "embed", true},
+		{"build", "build", true},
+		{"added", "add", false},
+		{"fixes", "fix", false},
+		{"updating", "update", false},
+		{"used", "use", false},
+		{"applied", "apply", false},
+		{"configured", "", false},
+		{"shared", "", false},
+		{"tested", "", false},
+		{"tied", "", false},
+	}
+
+	for _, tt := range tests {
+		if suggestion, ok := imperativeOf(tt.word); suggestion != tt.suggestion || ok != tt.ok {
+			t.Errorf("imperativeOf(%q) = %q, %v; want %q, %v", tt.word, suggestion, ok, tt.suggestion, tt.ok)
+		}
+	}
+}
+
+func TestIsGenerated(t *testing.T) {
+	tests := []struct {
+		message  string
+		expected bool
+	}{
+		{"Merge branch 'feature/login'\n", true},
+		{"\nMerge remote-tracking branch 'origin/main'\n", true},
+		{"Revert \"feat: add login\"\n\nThis reverts commit abc123.\n", true},
+		{"fixup! feat: add login\n", true},
+		{"squash! feat: add login\n", true},
+		{"amend! feat: add login\n\nfeat: add the login form\n", true},
+		{"feat: merge user records\n", false},
+		{"Add login\n\nMerge the forms.\n", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsGenerated(tt.message); got != tt.expected {
+			t.Errorf("IsGenerated(%q) = %v; want %v", tt.message, got, tt.expected)
+		}
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lint")
+	content := "# Team overrides\n" +
+		"header-max-length: warning 60\n" +
+		"header-imperative: off\n" +
+		"type-enum: error feat,fix,release\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	if got := cfg["header-max-length"]; got.Severity != SeverityWarning || got.Value != "60" {
+		t.Errorf("Unexpected header-max-length config: %+v", got)
+	}
+	if got := cfg["description-case"]; got.Severity != SeverityError {
+		t.Errorf("Expected defaults for unconfigured rules, got %+v", got)
+	}
+
+	issues := Lint("release: fixed packaging for the next version tag", cfg)
+	if HasErrors(issues) {
+		t.Errorf("Expected only warnings, got %v", issues)
+	}
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"Unknown rule", "no-such-rule: error\n"},
+		{"Unknown severity", "header-max-length: fatal\n"},
+		{"Invalid value", "header-max-length: error many\n"},
+		{"Missing colon", "header-max-length error\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "lint")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to write config: %v", err)
+			}
+			if _, err := LoadConfig(path); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+}
+
+func TestStripComments(t *testing.T) {
+	message := "fix: resolve crash\n\n- Guard nil pointer\n# Please enter the commit message\n" +
+		"# ------------------------ >8 ------------------------\ndiff --git a/x b/x\n"
+
+	expected := "fix: resolve crash\n\n- Guard nil pointer\n"
+	if got := StripComments(message); got != expected {
+		t.Errorf("StripComments() = %q; want %q", got, expected)
+	}
+}
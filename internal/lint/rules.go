@@ -0,0 +1,317 @@
+package lint
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// finding is a violation reported by a rule check before severity is applied
+type finding struct {
+	line    int
+	message string
+}
+
+// rule is a single lint check with its default configuration
+type rule struct {
+	id          string
+	description string
+	severity    Severity
+	value       string
+	needsCommit bool // skip when the header cannot be parsed
+	check       func(msg *message, value string) []finding
+	validateVal func(value string) error
+}
+
+// validate checks a configured rule value
+func (r *rule) validate(value string) error {
+	if r == nil || r.validateVal == nil {
+		return nil
+	}
+	return r.validateVal(value)
+}
+
+// findRule returns the rule with the given ID, or nil
+func findRule(id string) *rule {
+	for i := range rules {
+		if rules[i].id == id {
+			return &rules[i]
+		}
+	}
+	return nil
+}
+
+// defaultTypes are the commit types listed by the default prompt
+const defaultTypes = "feat,fix,docs,refactor,test,chore,perf,style,build,ci"
+
+var (
+	breakingLike = regexp.MustCompile(`(?i)^breaking[- _]?change`)
+	refsLike     = regexp.MustCompile(`(?i)^refs?\s*[:#]`)
+	ticketRef    = regexp.MustCompile(`^(#\d+|[A-Z][A-Z0-9]*-\d+)$`)
+)
+
+// rules lists every check in the order they are evaluated
+var rules = []rule{
+	{
+		id:          "header-format",
+		description: "header must follow \"<type>[(scope)][!]: <description>\"",
+		severity:    SeverityError,
+		check: func(msg *message, _ string) []finding {
+			if msg.headerLine() == "" {
+				return []finding{{msg.header + 1, "commit message is empty"}}
+			}
+			if msg.commit == nil {
+				return []finding{{msg.header + 1, fmt.Sprintf("header %q does not match \"<type>[(scope)]: <description>\"", msg.headerLine())}}
+			}
+			return nil
+		},
+	},
+	{
+		id:          "header-max-length",
+		description: "header must not be longer than the configured number of characters",
+		severity:    SeverityError,
+		value:       "50",
+		validateVal: validateInt,
+		check: func(msg *message, value string) []finding {
+			limit, _ := strconv.Atoi(value)
+			if n := utf8.RuneCountInString(msg.headerLine()); n > limit {
+				return []finding{{msg.header + 1, fmt.Sprintf("header is %d characters, maximum is %d", n, limit)}}
+			}
+			return nil
+		},
+	},
+	{
+		id:          "type-enum",
+		description: "type must be one of the configured comma-separated types",
+		severity:    SeverityError,
+		value:       defaultTypes,
+		needsCommit: true,
+		check: func(msg *message, value string) []finding {
+			for _, allowed := range strings.Split(value, ",") {
+				if strings.TrimSpace(allowed) == msg.commit.Header.Type {
+					return nil
+				}
+			}
+			return []finding{{msg.header + 1, fmt.Sprintf("type %q is not one of: %s", msg.commit.Header.Type, value)}}
+		},
+	},
+	{
+		id:          "header-imperative",
+		description: "description must use the imperative, present tense (\"add\", not \"added\" or \"adds\")",
+		severity:    SeverityWarning,
+		needsCommit: true,
+		check: func(msg *message, _ string) []finding {
+			word := strings.ToLower(firstWord(msg.commit.Header.Description))
+			if suggestion, ok := imperativeOf(word); !ok && suggestion != "" {
+				return []finding{{msg.header + 1, fmt.Sprintf("use the imperative mood: %q instead of %q", suggestion, word)}}
+			} else if !ok {
+				return []finding{{msg.header + 1, fmt.Sprintf("use the imperative mood instead of %q", word)}}
+			}
+			return nil
+		},
+	},
+	{
+		id:          "description-case",
+		description: "description must start with a lowercase letter",
+		severity:    SeverityError,
+		needsCommit: true,
+		check: func(msg *message, _ string) []finding {
+			first, _ := utf8.DecodeRuneInString(msg.commit.Header.Description)
+			// Acronyms such as "API" are allowed
+			word := firstWord(msg.commit.Header.Description)
+			if unicode.IsUpper(first) && strings.ToUpper(word) != word {
+				return []finding{{msg.header + 1, "description must start with a lowercase letter"}}
+			}
+			return nil
+		},
+	},
+	{
+		id:          "header-full-stop",
+		description: "header must not end with a period",
+		severity:    SeverityError,
+		check: func(msg *message, _ string) []finding {
+			if strings.HasSuffix(msg.headerLine(), ".") {
+				return []finding{{msg.header + 1, "header must not end with a period"}}
+			}
+			return nil
+		},
+	},
+	{
+		id:          "body-leading-blank",
+		description: "body must be separated from the header by a blank line",
+		severity:    SeverityError,
+		check: func(msg *message, _ string) []finding {
+			next := msg.header + 1
+			if next < len(msg.lines) && strings.TrimSpace(msg.lines[next]) != "" {
+				return []finding{{next + 1, "add a blank line between the header and the body"}}
+			}
+			return nil
+		},
+	},
+	{
+		id:          "body-max-line-length",
+		description: "body and footer lines must not be longer than the configured number of characters",
+		severity:    SeverityError,
+		value:       "72",
+		validateVal: validateInt,
+		check: func(msg *message, value string) []finding {
+			limit, _ := strconv.Atoi(value)
+			var findings []finding
+			numbers, lines := msg.bodyLines()
+			for i, line := range lines {
+				// Long URLs cannot be wrapped
+				if n := utf8.RuneCountInString(line); n > limit && !strings.Contains(line, "://") {
+					findings = append(findings, finding{numbers[i], fmt.Sprintf("line is %d characters, maximum is %d", n, limit)})
+				}
+			}
+			return findings
+		},
+	},
+	{
+		id:          "body-bullet-case",
+		description: "body list items must start with a capital letter",
+		severity:    SeverityWarning,
+		needsCommit: true,
+		check: func(msg *message, _ string) []finding {
+			var findings []finding
+			for _, bullet := range msg.commit.Bullets {
+				first, _ := utf8.DecodeRuneInString(bullet)
+				if unicode.IsLower(first) {
+					findings = append(findings, finding{msg.lineOf(bullet), fmt.Sprintf("list item %q must start with a capital letter", bullet)})
+				}
+			}
+			return findings
+		},
+	},
+	{
+		id:          "body-bullet-full-stop",
+		description: "body list items must not end with a period",
+		severity:    SeverityWarning,
+		needsCommit: true,
+		check: func(msg *message, _ string) []finding {
+			var findings []finding
+			for _, bullet := range msg.commit.Bullets {
+				if strings.HasSuffix(bullet, ".") {
+					findings = append(findings, finding{msg.lineOf(bullet), fmt.Sprintf("list item %q must not end with a period", bullet)})
+				}
+			}
+			return findings
+		},
+	},
+	{
+		id:          "breaking-change-format",
+		description: "breaking changes must use a \"BREAKING CHANGE: <description>\" footer",
+		severity:    SeverityError,
+		check: func(msg *message, _ string) []finding {
+			var findings []finding
+			numbers, lines := msg.bodyLines()
+			for i, line := range lines {
+				trimmed := strings.TrimSpace(line)
+				if !breakingLike.MatchString(trimmed) {
+					continue
+				}
+				token, description, found := strings.Cut(trimmed, ":")
+				if !found || (token != "BREAKING CHANGE" && token != "BREAKING-CHANGE") || strings.TrimSpace(description) == "" || !strings.HasPrefix(description, " ") {
+					findings = append(findings, finding{numbers[i], fmt.Sprintf("write breaking changes as \"BREAKING CHANGE: <description>\", got %q", trimmed)})
+				}
+			}
+			return findings
+		},
+	},
+	{
+		id:          "refs-format",
+		description: "ticket references must use a \"Refs: #123\" or \"Refs: ABC-123\" footer",
+		severity:    SeverityWarning,
+		check: func(msg *message, _ string) []finding {
+			var findings []finding
+			numbers, lines := msg.bodyLines()
+			for i, line := range lines {
+				trimmed := strings.TrimSpace(line)
+				if !refsLike.MatchString(trimmed) {
+					continue
+				}
+				token, value, found := strings.Cut(trimmed, ":")
+				if !found || token != "Refs" {
+					findings = append(findings, finding{numbers[i], fmt.Sprintf("write ticket references as \"Refs: #123\", got %q", trimmed)})
+					continue
+				}
+				for _, ref := range strings.Split(value, ",") {
+					if ref = strings.TrimSpace(ref); !ticketRef.MatchString(ref) {
+						findings = append(findings, finding{numbers[i], fmt.Sprintf("%q is not a ticket reference like #123 or ABC-123", ref)})
+					}
+				}
+			}
+			return findings
+		},
+	},
+}
+
+// validateInt checks that a rule value is a positive integer
+func validateInt(value string) error {
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		return fmt.Errorf("expected a positive number, got '%s'", value)
+	}
+	return nil
+}
+
+// firstWord returns the first whitespace-separated word of text
+func firstWord(text string) string {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[0]
+}
+
+// commonVerbs are verbs frequently used in commit headers, used to recognise third-person forms
+var commonVerbs = map[string]bool{
+	"add": true, "allow": true, "bump": true, "change": true, "clean": true, "create": true,
+	"delete": true, "disable": true, "document": true, "drop": true, "enable": true, "ensure": true,
+	"extract": true, "fix": true, "handle": true, "implement": true, "improve": true, "integrate": true,
+	"introduce": true, "make": true, "merge": true, "move": true, "optimize": true, "prevent": true,
+	"refactor": true, "release": true, "remove": true, "rename": true, "replace": true, "resolve": true,
+	"revert": true, "set": true, "simplify": true, "support": true, "update": true, "upgrade": true, "use": true,
+}
+
+// nonPastWords end in "ed" or "ing" but are valid imperatives or nouns
+var nonPastWords = map[string]bool{
+	"embed": true, "feed": true, "need": true, "seed": true, "shed": true, "speed": true, "shred": true,
+	"bring": true, "ring": true, "sing": true, "string": true, "swing": true, "thing": true, "ping": true,
+}
+
+// imperativeOf reports whether word looks imperative, and if not suggests the imperative form.
+// Suggestions only come from commonVerbs, "" when the verb is not known: stripping "ed" or "d"
+// cannot tell "configured" from "tested".
+func imperativeOf(word string) (string, bool) {
+	if word == "" || nonPastWords[word] || commonVerbs[word] {
+		return word, true
+	}
+	for _, suffix := range []string{"es", "s"} {
+		if base := strings.TrimSuffix(word, suffix); base != word && commonVerbs[base] {
+			return base, false
+		}
+	}
+	if base := strings.TrimSuffix(word, "ied"); base != word && len(base) > 1 {
+		return base + "y", false
+	}
+	for _, suffix := range []string{"ed", "d", "ing"} {
+		base := strings.TrimSuffix(word, suffix)
+		if base == word {
+			continue
+		}
+		if commonVerbs[base] {
+			return base, false
+		}
+		if commonVerbs[base+"e"] {
+			return base + "e", false
+		}
+		if suffix != "d" {
+			return "", false
+		}
+	}
+	return word, true
+}
@@ -0,0 +1,205 @@
+// Package output delivers generated prompts and commit messages to the clipboard, standard
+// output, a file, the git editor, the commit message file of git or a JSON consumer.
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"git-commit/internal/clipboard"
+	"git-commit/internal/config"
+	"git-commit/internal/git"
+)
+
+// Kinds of results
+const (
+	KindPrompt  = "prompt"  // a prompt to paste into an AI assistant
+	KindMessage = "message" // the answer of a model provider
+)
+
+// Result is the text a command produced, with what it describes
+type Result struct {
+	Kind   string `json:"kind"`             // KindPrompt or KindMessage
+	Prompt string `json:"prompt,omitempty"` // custom prompt name, "" for the default prompt
+	Source string `json:"source"`           // the described changes, e.g. "staged changes"
+	Text   string `json:"text"`             // the prompt or the answer of the model
+	Branch string `json:"branch,omitempty"` // branch name parsed from a message
+	Commit string `json:"commit,omitempty"` // commit message parsed from a message
+}
+
+// message returns the commit message of a result: the parsed one, or the whole text
+func (r Result) message() string {
+	if r.Commit != "" {
+		return r.Commit
+	}
+	return r.Text
+}
+
+// Sink delivers a result
+type Sink interface {
+	Write(r Result) error
+}
+
+// New returns the sink of an output specification accepted by config.ParseOutput.
+// Text goes to stdout and notices such as "Prompt copied to clipboard." to stderr.
+func New(spec string, stdout, stderr io.Writer) (Sink, error) {
+	sink, path, err := config.ParseOutput(spec)
+	if err != nil {
+		return nil, err
+	}
+	switch sink {
+	case config.OutputClipboard:
+		return &Clipboard{Out: stdout, Notices: stderr}, nil
+	case config.OutputFile:
+		return &File{Path: path, Notices: stderr}, nil
+	case config.OutputEditor:
+		return &Editor{Out: stdout}, nil
+	case config.OutputCommitTemplate:
+		return &CommitTemplate{Notices: stderr}, nil
+	case config.OutputJSON:
+		return &JSON{Out: stdout}, nil
+	}
+	return &Stdout{Out: stdout}, nil
+}
+
+// noun names the kind of a result in notices
+func noun(r Result) string {
+	if r.Kind == KindMessage {
+		return "Message"
+	}
+	return "Prompt"
+}
+
+// Stdout prints the text
+type Stdout struct {
+	Out io.Writer
+}
+
+func (s *Stdout) Write(r Result) error {
+	_, err := fmt.Fprintln(s.Out, r.Text)
+	return err
+}
+
+// Clipboard copies the text to the clipboard, printing it when no clipboard backend works
+type Clipboard struct {
+	Out     io.Writer
+	Notices io.Writer
+}
+
+func (c *Clipboard) Write(r Result) error {
+	if err := clipboard.Copy(r.Text); err != nil {
+		fmt.Fprintf(c.Notices, "Clipboard copy failed: %v\nPlease copy manually:\n", err)
+		_, err := fmt.Fprintf(c.Out, "---\n%s\n---\n", r.Text)
+		return err
+	}
+	_, err := fmt.Fprintf(c.Notices, "%s copied to clipboard.\n", noun(r))
+	return err
+}
+
+// File writes the text to a file, creating its directory
+type File struct {
+	Path    string
+	Notices io.Writer
+}
+
+func (f *File) Write(r Result) error {
+	if dir := filepath.Dir(f.Path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create directory for %s: %v", f.Path, err)
+		}
+	}
+	if err := os.WriteFile(f.Path, []byte(r.Text+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %v", f.Path, err)
+	}
+	_, err := fmt.Fprintf(f.Notices, "%s written to %s.\n", noun(r), f.Path)
+	return err
+}
+
+// Editor opens the text in the git editor and prints what was saved
+type Editor struct {
+	Out io.Writer
+}
+
+func (e *Editor) Write(r Result) error {
+	edited, err := Edit(r.Text)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprint(e.Out, edited)
+	return err
+}
+
+// CommitTemplate writes the commit message to COMMIT_EDITMSG in the git directory, where
+// "git commit -e -F" can pick it up
+type CommitTemplate struct {
+	Notices io.Writer
+}
+
+func (c *CommitTemplate) Write(r Result) error {
+	path, err := git.GitPath("COMMIT_EDITMSG")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, []byte(strings.TrimRight(r.message(), "\n")+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %v", path, err)
+	}
+	_, err = fmt.Fprintf(c.Notices, "%s written to %s, run \"git commit -e -F %s\" to use it.\n", noun(r), path, path)
+	return err
+}
+
+// JSON prints the result as a JSON object on one line
+type JSON struct {
+	Out io.Writer
+}
+
+func (j *JSON) Write(r Result) error {
+	return json.NewEncoder(j.Out).Encode(r)
+}
+
+// Edit opens text in the git editor, see git.Editor, and returns the saved text
+func Edit(text string) (string, error) {
+	editor, err := git.Editor()
+	if err != nil {
+		return "", err
+	}
+
+	file, err := os.CreateTemp("", "git-commit-*.md")
+	if err != nil {
+		return "", fmt.Errorf("failed to create file to edit: %v", err)
+	}
+	defer os.Remove(file.Name())
+	_, err = file.WriteString(text)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to write file to edit: %v", err)
+	}
+
+	// Like git, run the editor through the shell so it may carry arguments, e.g. "code --wait"
+	cmd := exec.Command("sh", "-c", editor+` "$@"`, editor, file.Name())
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/c", editor+" "+file.Name())
+	}
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0); err == nil {
+		// Standard output may be a pipe, the editor still needs the terminal
+		defer tty.Close()
+		cmd.Stdin, cmd.Stdout = tty, tty
+	}
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("editor %s failed: %v", editor, err)
+	}
+
+	edited, err := os.ReadFile(file.Name())
+	if err != nil {
+		return "", fmt.Errorf("failed to read edited file: %v", err)
+	}
+	return string(edited), nil
+}
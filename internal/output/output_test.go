@@ -0,0 +1,134 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"git-commit/internal/clipboard"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		spec     string
+		expected string
+	}{
+		{"stdout", "*output.Stdout"},
+		{"clipboard", "*output.Clipboard"},
+		{"file:out.md", "*output.File"},
+		{"editor", "*output.Editor"},
+		{"commit-template", "*output.CommitTemplate"},
+		{"json", "*output.JSON"},
+	}
+
+	for _, tt := range tests {
+		sink, err := New(tt.spec, nil, nil)
+		if err != nil {
+			t.Errorf("New(%q) error = %v", tt.spec, err)
+		} else if got := fmt.Sprintf("%T", sink); got != tt.expected {
+			t.Errorf("New(%q) = %s; want %s", tt.spec, got, tt.expected)
+		}
+	}
+	if _, err := New("printer", nil, nil); err == nil {
+		t.Error("New(printer) should fail")
+	}
+}
+
+func TestStdoutAndJSON(t *testing.T) {
+	r := Result{Kind: KindMessage, Source: "staged changes", Text: "Branch: feat/x\n\nfeat: add x", Branch: "feat/x", Commit: "feat: add x"}
+
+	var out bytes.Buffer
+	if err := (&Stdout{Out: &out}).Write(r); err != nil || out.String() != r.Text+"\n" {
+		t.Errorf("Stdout wrote %q, %v", out.String(), err)
+	}
+
+	out.Reset()
+	if err := (&JSON{Out: &out}).Write(r); err != nil {
+		t.Fatalf("JSON error = %v", err)
+	}
+	var decoded map[string]string
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil {
+		t.Fatalf("JSON wrote invalid JSON %q: %v", out.String(), err)
+	}
+	expected := map[string]string{"kind": "message", "source": "staged changes", "text": r.Text, "branch": "feat/x", "commit": "feat: add x"}
+	if fmt.Sprint(decoded) != fmt.Sprint(expected) {
+		t.Errorf("JSON wrote %v; want %v", decoded, expected)
+	}
+}
+
+func TestClipboard(t *testing.T) {
+	fake := &clipboard.Fake{}
+	defer clipboard.Use(fake)()
+
+	var out, notices bytes.Buffer
+	sink := &Clipboard{Out: &out, Notices: &notices}
+	if err := sink.Write(Result{Kind: KindPrompt, Text: "Describe the changes"}); err != nil {
+		t.Fatalf("Clipboard error = %v", err)
+	}
+	if fake.Text != "Describe the changes" || out.Len() != 0 || notices.String() != "Prompt copied to clipboard.\n" {
+		t.Errorf("Clipboard copied %q, printed %q and noticed %q", fake.Text, out.String(), notices.String())
+	}
+
+	// A failing backend falls back to printing the text
+	fake.Err = errors.New("xclip failed")
+	out.Reset()
+	if err := sink.Write(Result{Kind: KindPrompt, Text: "Describe the changes"}); err != nil {
+		t.Fatalf("Clipboard error = %v", err)
+	}
+	if out.String() != "---\nDescribe the changes\n---\n" {
+		t.Errorf("Clipboard printed %q", out.String())
+	}
+}
+
+func TestFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out", "prompt.md")
+	var notices bytes.Buffer
+	if err := (&File{Path: path, Notices: &notices}).Write(Result{Kind: KindPrompt, Text: "Describe the changes"}); err != nil {
+		t.Fatalf("File error = %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "Describe the changes\n" {
+		t.Errorf("File wrote %q", data)
+	}
+	if notices.String() != "Prompt written to "+path+".\n" {
+		t.Errorf("File notice = %q", notices.String())
+	}
+}
+
+func TestEditorAndCommitTemplate(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	wd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+	if out, err := exec.Command("git", "init", "-q").CombinedOutput(); err != nil {
+		t.Fatalf("git init failed: %v\n%s", err, out)
+	}
+
+	// The editor appends a line, standing in for the user
+	t.Setenv("GIT_EDITOR", `f() { echo "edited" >> "$1"; }; f`)
+	var out bytes.Buffer
+	if err := (&Editor{Out: &out}).Write(Result{Text: "draft\n"}); err != nil {
+		t.Fatalf("Editor error = %v", err)
+	}
+	if out.String() != "draft\nedited\n" {
+		t.Errorf("Editor printed %q", out.String())
+	}
+
+	var notices bytes.Buffer
+	r := Result{Kind: KindMessage, Text: "Branch: feat/x\n\nfeat: add x", Commit: "feat: add x"}
+	if err := (&CommitTemplate{Notices: &notices}).Write(r); err != nil {
+		t.Fatalf("CommitTemplate error = %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(".git", "COMMIT_EDITMSG")); string(data) != "feat: add x\n" {
+		t.Errorf("CommitTemplate wrote %q", data)
+	}
+}
@@ -0,0 +1,372 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Errors wrapped by FormatError, use errors.Is to check which rule was violated
+var (
+	ErrEmptyResponse = errors.New("empty response")
+	ErrMissingBranch = errors.New("missing branch name")
+	ErrMissingHeader = errors.New("missing commit header")
+	ErrInvalidHeader = errors.New("invalid commit header")
+)
+
+// FormatError describes where a response or message violates the expected format
+type FormatError struct {
+	Err  error  // one of the Err* sentinels above
+	Line int    // 1-based line number in the input, 0 when not tied to a line
+	Text string // the offending line, if any
+}
+
+func (e *FormatError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("line %d: %v: %q", e.Line, e.Err, e.Text)
+	}
+	return e.Err.Error()
+}
+
+func (e *FormatError) Unwrap() error {
+	return e.Err
+}
+
+// Header is a parsed Conventional Commits header: "<type>[(scope)][!]: <description>"
+type Header struct {
+	Type        string
+	Scope       string
+	Description string
+	Breaking    bool
+}
+
+// String formats the header back into a single line
+func (h Header) String() string {
+	var b strings.Builder
+	b.WriteString(h.Type)
+	if h.Scope != "" {
+		b.WriteString("(" + h.Scope + ")")
+	}
+	if h.Breaking {
+		b.WriteString("!")
+	}
+	b.WriteString(": " + h.Description)
+	return b.String()
+}
+
+// Footer is a git trailer such as "Refs: #123" or "BREAKING CHANGE: drop v1"
+type Footer struct {
+	Token string
+	Value string
+}
+
+// Commit is a parsed commit message
+type Commit struct {
+	Header  Header
+	Body    string   // body text between the header and the footers
+	Bullets []string // markdown list items found in the body
+	Footers []Footer
+}
+
+// Breaking reports whether the commit is marked as a breaking change in the header or a footer
+func (c Commit) Breaking() bool {
+	if c.Header.Breaking {
+		return true
+	}
+	for _, footer := range c.Footers {
+		if isBreakingToken(footer.Token) {
+			return true
+		}
+	}
+	return false
+}
+
+// String formats the commit back into a message suitable for git commit -F
+func (c Commit) String() string {
+	parts := []string{c.Header.String()}
+	if c.Body != "" {
+		parts = append(parts, c.Body)
+	}
+	if len(c.Footers) > 0 {
+		var footers []string
+		for _, footer := range c.Footers {
+			footers = append(footers, footer.Token+": "+footer.Value)
+		}
+		parts = append(parts, strings.Join(footers, "\n"))
+	}
+	return strings.Join(parts, "\n\n") + "\n"
+}
+
+// Result is the structured answer of a model: a branch name and a commit message
+type Result struct {
+	Branch string
+	Commit Commit
+}
+
+var (
+	headerPattern = regexp.MustCompile(`^([A-Za-z]+)(?:\(([^()]*)\))?(!)?: (\S.*)$`)
+	footerPattern = regexp.MustCompile(`^(BREAKING CHANGE|BREAKING-CHANGE|[A-Za-z][A-Za-z0-9-]*)(: | #)(.*)$`)
+	branchLabel   = regexp.MustCompile(`(?i)^(?:[-*#>\s]*)(?:\*\*)?branch(?: name)?(?:\*\*)?\s*:\s*(?:\*\*)?\s*(.*)$`)
+	messageLabel  = regexp.MustCompile(`(?i)^(?:[-*#>\s]*)(?:\*\*)?commit(?: message)?(?:\*\*)?\s*:\s*(?:\*\*)?\s*(.*)$`)
+	branchPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*/[A-Za-z0-9._/-]+$`)
+	bulletPattern = regexp.MustCompile(`^\s*[-*]\s+(.*)$`)
+)
+
+// ParseHeader parses a Conventional Commits header line
+func ParseHeader(line string) (Header, error) {
+	match := headerPattern.FindStringSubmatch(strings.TrimSpace(line))
+	if match == nil {
+		return Header{}, &FormatError{Err: ErrInvalidHeader, Line: 1, Text: line}
+	}
+	return Header{
+		Type:        match[1],
+		Scope:       match[2],
+		Breaking:    match[3] == "!",
+		Description: strings.TrimSpace(match[4]),
+	}, nil
+}
+
+// ParseMessage parses a commit message into header, body, bullets and footers
+func ParseMessage(message string) (*Commit, error) {
+	lines := dedent(splitLines(message))
+
+	// The header is the first non-empty line
+	start := 0
+	for start < len(lines) && strings.TrimSpace(lines[start]) == "" {
+		start++
+	}
+	if start == len(lines) {
+		return nil, &FormatError{Err: ErrMissingHeader}
+	}
+
+	header, err := ParseHeader(lines[start])
+	if err != nil {
+		return nil, &FormatError{Err: ErrInvalidHeader, Line: start + 1, Text: lines[start]}
+	}
+
+	commit := &Commit{Header: header}
+	paragraphs := splitParagraphs(lines[start+1:])
+
+	// Footers form the last paragraph when every line is a trailer or continues one
+	if n := len(paragraphs); n > 0 {
+		if footers, ok := parseFooters(paragraphs[n-1]); ok {
+			commit.Footers = footers
+			paragraphs = paragraphs[:n-1]
+		}
+	}
+
+	var body []string
+	for _, paragraph := range paragraphs {
+		body = append(body, strings.Join(paragraph, "\n"))
+		for _, line := range paragraph {
+			if match := bulletPattern.FindStringSubmatch(line); match != nil {
+				commit.Bullets = append(commit.Bullets, strings.TrimSpace(match[1]))
+			}
+		}
+	}
+	commit.Body = strings.Join(body, "\n\n")
+
+	return commit, nil
+}
+
+// Parse extracts the branch name and commit message from a raw model response.
+// Code fences, "Branch Name:"/"Commit Message:" labels and surrounding prose are tolerated.
+func Parse(response string) (*Result, error) {
+	lines := splitLines(response)
+	if strings.TrimSpace(response) == "" {
+		return nil, &FormatError{Err: ErrEmptyResponse}
+	}
+
+	result := &Result{}
+	branchLine := -1
+	inFence := false
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			inFence = !inFence
+			continue
+		}
+
+		// An explicit "Branch Name:" label wins, otherwise take the first branch-looking line
+		if match := branchLabel.FindStringSubmatch(trimmed); match != nil {
+			if value := cleanValue(match[1]); value != "" {
+				result.Branch, branchLine = value, i
+				break
+			}
+			// The value may be on the following line
+			if next := nextNonEmpty(lines, i+1); next >= 0 {
+				result.Branch, branchLine = cleanValue(lines[next]), next
+				break
+			}
+		}
+		if result.Branch == "" && branchPattern.MatchString(cleanValue(trimmed)) {
+			result.Branch, branchLine = cleanValue(trimmed), i
+			break
+		}
+	}
+	if result.Branch == "" {
+		return nil, &FormatError{Err: ErrMissingBranch}
+	}
+
+	// The message starts at the first header after the branch and ends at the closing fence
+	messageStart := -1
+	for i := branchLine + 1; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if match := messageLabel.FindStringSubmatch(trimmed); match != nil {
+			if value := cleanValue(match[1]); value != "" && headerPattern.MatchString(value) {
+				lines[i] = value
+				messageStart = i
+				break
+			}
+			continue
+		}
+		if headerPattern.MatchString(strings.Trim(trimmed, "`")) {
+			lines[i] = strings.Trim(trimmed, "`")
+			messageStart = i
+			break
+		}
+	}
+	if messageStart < 0 {
+		// Report the first line that looked like an attempt at a header
+		for i := branchLine + 1; i < len(lines); i++ {
+			trimmed := strings.TrimSpace(lines[i])
+			if trimmed != "" && !strings.HasPrefix(trimmed, "```") && !messageLabel.MatchString(trimmed) {
+				return nil, &FormatError{Err: ErrInvalidHeader, Line: i + 1, Text: lines[i]}
+			}
+		}
+		return nil, &FormatError{Err: ErrMissingHeader}
+	}
+
+	messageEnd := len(lines)
+	for i := messageStart + 1; i < len(lines); i++ {
+		if strings.HasPrefix(strings.TrimSpace(lines[i]), "```") {
+			messageEnd = i
+			break
+		}
+	}
+
+	commit, err := ParseMessage(strings.Join(lines[messageStart:messageEnd], "\n"))
+	if err != nil {
+		var formatErr *FormatError
+		if errors.As(err, &formatErr) && formatErr.Line > 0 {
+			formatErr.Line += messageStart
+		}
+		return nil, err
+	}
+	result.Commit = *commit
+
+	return result, nil
+}
+
+// ParseAnswer parses a model answer to commit with: the branch name and commit message of Parse,
+// or a Conventional Commits message alone, as answered by message-only prompts, with no branch.
+// An answer naming no branch is reported with the error of ParseMessage, any other with that of Parse.
+func ParseAnswer(answer string) (*Result, error) {
+	result, err := Parse(answer)
+	if !errors.Is(err, ErrMissingBranch) {
+		return result, err
+	}
+	commit, err := ParseMessage(answer)
+	if err != nil {
+		return nil, err
+	}
+	return &Result{Commit: *commit}, nil
+}
+
+// parseFooters parses a paragraph of trailers, returning false if any line is not a trailer
+func parseFooters(paragraph []string) ([]Footer, bool) {
+	var footers []Footer
+	for _, line := range paragraph {
+		match := footerPattern.FindStringSubmatch(line)
+		if match == nil {
+			// Indented lines continue the previous footer value
+			if len(footers) > 0 && strings.HasPrefix(line, " ") {
+				footers[len(footers)-1].Value += "\n" + strings.TrimSpace(line)
+				continue
+			}
+			return nil, false
+		}
+		value := match[3]
+		if match[2] == " #" {
+			value = "#" + value
+		}
+		footers = append(footers, Footer{Token: match[1], Value: strings.TrimSpace(value)})
+	}
+	return footers, len(footers) > 0
+}
+
+// isBreakingToken reports whether a footer token marks a breaking change
+func isBreakingToken(token string) bool {
+	return token == "BREAKING CHANGE" || token == "BREAKING-CHANGE"
+}
+
+// splitLines splits text into lines, normalizing Windows line endings
+func splitLines(text string) []string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	return strings.Split(text, "\n")
+}
+
+// splitParagraphs groups lines into blank-line separated paragraphs, dropping the blank lines
+func splitParagraphs(lines []string) [][]string {
+	var paragraphs [][]string
+	var current []string
+	for _, line := range lines {
+		line = strings.TrimRight(line, " \t")
+		if line == "" {
+			if len(current) > 0 {
+				paragraphs = append(paragraphs, current)
+				current = nil
+			}
+			continue
+		}
+		current = append(current, line)
+	}
+	if len(current) > 0 {
+		paragraphs = append(paragraphs, current)
+	}
+	return paragraphs
+}
+
+// dedent removes the indentation shared by all non-empty lines
+func dedent(lines []string) []string {
+	indent := -1
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		n := len(line) - len(strings.TrimLeft(line, " \t"))
+		if indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	if indent <= 0 {
+		return lines
+	}
+
+	result := make([]string, len(lines))
+	for i, line := range lines {
+		if len(line) >= indent {
+			result[i] = line[indent:]
+		} else {
+			result[i] = strings.TrimLeft(line, " \t")
+		}
+	}
+	return result
+}
+
+// nextNonEmpty returns the index of the first non-empty, non-fence line at or after start, or -1
+func nextNonEmpty(lines []string, start int) int {
+	for i := start; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if trimmed != "" && !strings.HasPrefix(trimmed, "```") {
+			return i
+		}
+	}
+	return -1
+}
+
+// cleanValue strips markdown emphasis, inline code and quotes around a value
+func cleanValue(value string) string {
+	return strings.Trim(strings.TrimSpace(value), "*`\"' ")
+}
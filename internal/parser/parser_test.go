@@ -0,0 +1,201 @@
+package parser
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseHeader(t *testing.T) {
+	tests := []struct {
+		name     string
+		line     string
+		expected Header
+		wantErr  bool
+	}{
+		{"Type only", "docs: add installation guide", Header{Type: "docs", Description: "add installation guide"}, false},
+		{"With scope", "feat(auth): implement user login", Header{Type: "feat", Scope: "auth", Description: "implement user login"}, false},
+		{"Breaking with scope", "feat(api)!: remove v1 endpoints", Header{Type: "feat", Scope: "api", Breaking: true, Description: "remove v1 endpoints"}, false},
+		{"Breaking without scope", "refactor!: drop legacy config", Header{Type: "refactor", Breaking: true, Description: "drop legacy config"}, false},
+		{"Missing space", "feat:add login", Header{}, true},
+		{"Missing type", ": add login", Header{}, true},
+		{"Plain sentence", "Add login", Header{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header, err := ParseHeader(tt.line)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidHeader) {
+					t.Errorf("ParseHeader(%q) error = %v; want ErrInvalidHeader", tt.line, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseHeader(%q) error = %v", tt.line, err)
+			}
+			if header != tt.expected {
+				t.Errorf("ParseHeader(%q) = %+v; want %+v", tt.line, header, tt.expected)
+			}
+			if header.String() != tt.line {
+				t.Errorf("Header.String() = %q; want %q", header.String(), tt.line)
+			}
+		})
+	}
+}
+
+func TestParseMessage(t *testing.T) {
+	message := "fix(auth): resolve login validation\n\n" +
+		"- Fix password validation\n" +
+		"- Update error messages\n\n" +
+		"BREAKING CHANGE: tokens issued before the fix are rejected\n" +
+		"Refs: #456\n"
+
+	commit, err := ParseMessage(message)
+	if err != nil {
+		t.Fatalf("ParseMessage() error = %v", err)
+	}
+
+	if commit.Header.Type != "fix" || commit.Header.Scope != "auth" {
+		t.Errorf("Unexpected header: %+v", commit.Header)
+	}
+	expectedBullets := []string{"Fix password validation", "Update error messages"}
+	if !reflect.DeepEqual(commit.Bullets, expectedBullets) {
+		t.Errorf("Bullets = %q; want %q", commit.Bullets, expectedBullets)
+	}
+	expectedFooters := []Footer{
+		{Token: "BREAKING CHANGE", Value: "tokens issued before the fix are rejected"},
+		{Token: "Refs", Value: "#456"},
+	}
+	if !reflect.DeepEqual(commit.Footers, expectedFooters) {
+		t.Errorf("Footers = %+v; want %+v", commit.Footers, expectedFooters)
+	}
+	if !commit.Breaking() {
+		t.Error("Expected commit with BREAKING CHANGE footer to be breaking")
+	}
+	if commit.String() != message {
+		t.Errorf("Commit.String() = %q; want %q", commit.String(), message)
+	}
+}
+
+func TestParseMessageHashFooter(t *testing.T) {
+	commit, err := ParseMessage("chore: bump deps\n\nCloses #12")
+	if err != nil {
+		t.Fatalf("ParseMessage() error = %v", err)
+	}
+	if len(commit.Footers) != 1 || commit.Footers[0] != (Footer{Token: "Closes", Value: "#12"}) {
+		t.Errorf("Unexpected footers: %+v", commit.Footers)
+	}
+	if commit.Body != "" {
+		t.Errorf("Expected empty body, got %q", commit.Body)
+	}
+}
+
+func TestParse(t *testing.T) {
+	expectedBullets := []string{"Add Stripe service module", "Create payment component"}
+
+	tests := []struct {
+		name     string
+		response string
+	}{
+		{
+			"Labeled format",
+			"Branch Name: feature/add-payment-gateway\nCommit Message:\nfeat(payment): integrate stripe api\n\n- Add Stripe service module\n- Create payment component\n",
+		},
+		{
+			"Branch on first line",
+			"feature/add-payment-gateway\nfeat(payment): integrate stripe api\n\n- Add Stripe service module\n- Create payment component",
+		},
+		{
+			"Code fence with prose",
+			"Sure! Here is the result:\n\n```\nfeature/add-payment-gateway\nfeat(payment): integrate stripe api\n\n- Add Stripe service module\n- Create payment component\n```\n\nLet me know if you need changes.",
+		},
+		{
+			"Markdown labels and indented message",
+			"**Branch Name:** `feature/add-payment-gateway`\n\n**Commit Message:**\n```\n   feat(payment): integrate stripe api\n\n   - Add Stripe service module\n   - Create payment component\n```",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Parse(tt.response)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if result.Branch != "feature/add-payment-gateway" {
+				t.Errorf("Branch = %q", result.Branch)
+			}
+			if result.Commit.Header.String() != "feat(payment): integrate stripe api" {
+				t.Errorf("Header = %q", result.Commit.Header.String())
+			}
+			if !reflect.DeepEqual(result.Commit.Bullets, expectedBullets) {
+				t.Errorf("Bullets = %q; want %q", result.Commit.Bullets, expectedBullets)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		expected error
+		line     int
+	}{
+		{"Empty", "  \n", ErrEmptyResponse, 0},
+		{"No branch", "feat: add login", ErrMissingBranch, 0},
+		{"No header", "Branch Name: feature/login\nCommit Message:\n", ErrMissingHeader, 0},
+		{"Invalid header", "feature/login\nAdd login form", ErrInvalidHeader, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.response)
+			if !errors.Is(err, tt.expected) {
+				t.Fatalf("Parse() error = %v; want %v", err, tt.expected)
+			}
+			var formatErr *FormatError
+			if !errors.As(err, &formatErr) {
+				t.Fatalf("Expected *FormatError, got %T", err)
+			}
+			if formatErr.Line != tt.line {
+				t.Errorf("FormatError.Line = %d; want %d", formatErr.Line, tt.line)
+			}
+		})
+	}
+}
+
+func TestParseAnswer(t *testing.T) {
+	tests := []struct {
+		name     string
+		answer   string
+		branch   string
+		message  string
+		expected error
+	}{
+		{"Branch and commit", "feature/login\nfeat: add login\n\n- Add form", "feature/login", "feat: add login\n\n- Add form\n", nil},
+		{"Message only", "fix(api): handle timeouts\n\nRefs: #12", "", "fix(api): handle timeouts\n\nRefs: #12\n", nil},
+		{"Empty", "  \n", "", "", ErrEmptyResponse},
+		{"Prose", "add login", "", "", ErrInvalidHeader},
+		{"Branch without header", "feature/login\nAdd login form", "", "", ErrInvalidHeader},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ParseAnswer(tt.answer)
+			if tt.expected != nil {
+				var formatErr *FormatError
+				if !errors.Is(err, tt.expected) || !errors.As(err, &formatErr) {
+					t.Fatalf("ParseAnswer() error = %v; want a FormatError for %v", err, tt.expected)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseAnswer() error = %v", err)
+			}
+			if result.Branch != tt.branch || result.Commit.String() != tt.message {
+				t.Errorf("ParseAnswer() = %q, %q; want %q, %q", result.Branch, result.Commit.String(), tt.branch, tt.message)
+			}
+		})
+	}
+}
@@ -0,0 +1,214 @@
+package prompt
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"git-commit/internal/diff"
+	"git-commit/internal/git"
+	"git-commit/internal/ignore"
+)
+
+// @context: sources read from git rather than from a path of the working tree
+const (
+	ContextBlame    = "blame"    // blame of the lines changed or removed by the described changes
+	ContextPrevious = "previous" // every changed file as it was before the changes
+)
+
+// DefaultLogCount is the number of commits of @context:log without a count
+const DefaultLogCount = 10
+
+// logContext matches the log(<n>) context source
+var logContext = regexp.MustCompile(`^log(?:\((\d+)\))?$`)
+
+// isWorkTreePath reports whether an @context: argument names a file or directory of the working tree.
+// Such paths win over the git sources, so existing files named like "blame" keep working.
+func isWorkTreePath(repo *git.Repo, spec string) bool {
+	if filepath.IsAbs(spec) {
+		return true
+	}
+	_, err := os.Stat(repo.Path(spec))
+	return err == nil
+}
+
+// isGitContext reports whether an @context: argument is one of the git sources of gitContextBlock
+func isGitContext(spec string) bool {
+	if logContext.MatchString(spec) || spec == ContextBlame || spec == ContextPrevious {
+		return true
+	}
+	if _, path, found := strings.Cut(spec, ":"); found && path != "" {
+		return true
+	}
+	return strings.ContainsAny(spec, "*?[")
+}
+
+// gitContextBlock returns the context of an @context: argument that is not a path of the working
+// tree: log(<n>), blame, previous, <rev>:<path> or a glob. ok is false for any other argument.
+func gitContextBlock(repo *git.Repo, spec string, src diff.Source) (block string, ok bool, err error) {
+	if !isGitContext(spec) {
+		return "", false, nil
+	}
+	if m := logContext.FindStringSubmatch(spec); m != nil {
+		block, err = logBlock(m[1])
+	} else if spec == ContextBlame {
+		block, err = blameBlock(repo, src)
+	} else if spec == ContextPrevious {
+		block, err = previousBlock(repo, src)
+	} else if rev, path, found := strings.Cut(spec, ":"); found && path != "" {
+		block, err = revisionBlock(rev, path)
+	} else {
+		block, err = globBlock(repo, spec)
+	}
+	return block, true, err
+}
+
+// logBlock returns the last count commits, DefaultLogCount when count is ""
+func logBlock(count string) (string, error) {
+	n := DefaultLogCount
+	if count != "" {
+		var err error
+		if n, err = strconv.Atoi(count); err != nil || n < 1 {
+			return "", fmt.Errorf("invalid log count %q, expected a positive number", count)
+		}
+	}
+	log, err := git.Log(n)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("<log count=\"%d\">\n%s</log>", n, log), nil
+}
+
+// revisionBlock returns a file as it is at rev, or in the index when rev is ""
+func revisionBlock(rev, path string) (string, error) {
+	content, err := git.ShowFile(rev, path)
+	if err != nil {
+		return "", fmt.Errorf("error reading context file %s at %s: %v", path, revisionName(rev), err)
+	}
+	return fmt.Sprintf("<context file=\"%s\" revision=\"%s\">\n%s\n</context>",
+		attrEscaper.Replace(path), attrEscaper.Replace(revisionName(rev)), content), nil
+}
+
+// revisionName describes a revision for the context tags, "index" for ""
+func revisionName(rev string) string {
+	if rev == "" {
+		return "index"
+	}
+	return rev
+}
+
+// globBlock returns every tracked or untracked, not ignored, file matching a glob such as docs/**/*.md.
+// Globs follow the .gitignore syntax, like the path= argument of @diff.
+func globBlock(repo *git.Repo, glob string) (string, error) {
+	files, err := git.ListFiles()
+	if err != nil {
+		return "", err
+	}
+	matcher, err := ignore.NewMatcher([]string{glob})
+	if err != nil {
+		return "", fmt.Errorf("invalid context pattern: %v", err)
+	}
+	var blocks []string
+	for _, file := range files {
+		if !matcher.Match(file, false) {
+			continue
+		}
+		if _, err := os.Lstat(repo.Path(file)); os.IsNotExist(err) {
+			// Tracked files deleted from the working tree have nothing to show
+			continue
+		}
+		block, err := contextBlock(repo, file, diff.Source{})
+		if err != nil {
+			return "", err
+		}
+		blocks = append(blocks, block)
+	}
+	if len(blocks) == 0 {
+		return "", fmt.Errorf("no files match context pattern %s", glob)
+	}
+	return strings.Join(blocks, "\n"), nil
+}
+
+// changedFiles returns the files of src that existed before the changes, with the revision holding them.
+// The changes are loaded without context lines, so the hunks cover the changed lines only.
+func changedFiles(repo *git.Repo, src diff.Source) (string, []*diff.File, error) {
+	base, err := src.Base()
+	if err != nil {
+		return "", nil, err
+	}
+	d, err := diff.LoadWith(repo, diff.Options{Source: src, ContextLines: 0})
+	if err != nil {
+		return "", nil, err
+	}
+	var files []*diff.File
+	for _, f := range d.Files {
+		if f.Status != diff.Added && !f.Binary {
+			files = append(files, f)
+		}
+	}
+	return base, files, nil
+}
+
+// previousBlock returns every changed file as it was before the changes
+func previousBlock(repo *git.Repo, src diff.Source) (string, error) {
+	base, files, err := changedFiles(repo, src)
+	if err != nil {
+		return "", err
+	}
+	var blocks []string
+	for _, f := range files {
+		block, err := revisionBlock(base, f.OldPath)
+		if err != nil {
+			return "", err
+		}
+		blocks = append(blocks, block)
+	}
+	if len(blocks) == 0 {
+		return fmt.Sprintf("None of the files changed in the %s existed before.", src), nil
+	}
+	return strings.Join(blocks, "\n"), nil
+}
+
+// blameBlock returns the blame of the lines changed or removed by src, so the model can see
+// when and by whom they were written. Unstaged changes are blamed in their index version, where lines
+// that are staged but not committed show as "Not Committed Yet".
+func blameBlock(repo *git.Repo, src diff.Source) (string, error) {
+	base, files, err := changedFiles(repo, src)
+	if err != nil {
+		return "", err
+	}
+	var blocks []string
+	for _, f := range files {
+		// The lines of unstaged changes are numbered in the index version, which may differ from HEAD
+		var indexed string
+		if base == "" {
+			if indexed, err = git.ShowFile("", f.OldPath); err != nil {
+				return "", fmt.Errorf("error reading %s from the index: %v", f.OldPath, err)
+			}
+		}
+		for _, h := range f.Hunks {
+			if h.OldLines == 0 {
+				// Pure additions replace no lines
+				continue
+			}
+			var blame string
+			if base == "" {
+				blame, err = git.BlameContents(repo.Path(), f.OldPath, indexed, h.OldStart, h.OldLines)
+			} else {
+				blame, err = git.Blame(repo.Path(), base, f.OldPath, h.OldStart, h.OldLines)
+			}
+			if err != nil {
+				return "", fmt.Errorf("error blaming %s: %v", f.OldPath, err)
+			}
+			blocks = append(blocks, fmt.Sprintf("<blame file=\"%s\" revision=\"%s\" lines=\"%d-%d\">\n%s</blame>",
+				attrEscaper.Replace(f.OldPath), attrEscaper.Replace(revisionName(base)), h.OldStart, h.OldStart+h.OldLines-1, blame))
+		}
+	}
+	if len(blocks) == 0 {
+		return fmt.Sprintf("No lines were changed or removed in the %s, there is nothing to blame.", src), nil
+	}
+	return strings.Join(blocks, "\n"), nil
+}
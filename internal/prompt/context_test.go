@@ -0,0 +1,201 @@
+package prompt
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"git-commit/internal/diff"
+	"git-commit/internal/git"
+)
+
+// runGit runs a git command in the test repository with a fixed identity
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=Ada", "GIT_AUTHOR_EMAIL=ada@example.com",
+		"GIT_COMMITTER_NAME=Ada", "GIT_COMMITTER_EMAIL=ada@example.com")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %s failed: %v\n%s", strings.Join(args, " "), err, out)
+	}
+}
+
+// setupGitRepo creates a repository with two commits of docs/api.md and main.go,
+// and staged changes to main.go
+func setupGitRepo(t *testing.T) *git.Repo {
+	t.Helper()
+	repo := setupTestDir(t)
+	write := func(path, content string) {
+		full := filepath.Join(repo.Root, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	runGit(t, repo.Root, "init", "-q")
+	write("docs/api.md", "# API v1\n")
+	write("main.go", "package main\n\nfunc main() {\n\tprintln(\"old\")\n}\n")
+	runGit(t, repo.Root, "add", "docs", "main.go")
+	runGit(t, repo.Root, "commit", "-q", "-m", "Add API docs")
+	write("docs/api.md", "# API v2\n")
+	runGit(t, repo.Root, "commit", "-q", "-am", "Document API v2")
+	write("main.go", "package main\n\nfunc main() {\n\tprintln(\"new\")\n}\n")
+	runGit(t, repo.Root, "add", "main.go")
+	return repo
+}
+
+func TestIsGitContext(t *testing.T) {
+	tests := []struct {
+		spec     string
+		expected bool
+	}{
+		{"docs/api.md", false},
+		{"docs/", false},
+		{"log", true},
+		{"log(20)", true},
+		{"logs", false},
+		{"blame", true},
+		{"previous", true},
+		{"HEAD~1:docs/api.md", true},
+		{":docs/api.md", true},
+		{"docs/**/*.md", true},
+	}
+
+	for _, tt := range tests {
+		if got := isGitContext(tt.spec); got != tt.expected {
+			t.Errorf("isGitContext(%q) = %v; want %v", tt.spec, got, tt.expected)
+		}
+	}
+}
+
+func TestGitContextBlock(t *testing.T) {
+	repo := setupGitRepo(t)
+
+	tests := []struct {
+		spec     string
+		contains []string
+	}{
+		{"HEAD~1:docs/api.md", []string{"<context file=\"docs/api.md\" revision=\"HEAD~1\">\n# API v1\n"}},
+		{"docs/**/*.md", []string{"<context file=\"docs/api.md\">\n# API v2\n"}},
+		{"log(1)", []string{"<log count=\"1\">\n", "Ada Document API v2\n</log>"}},
+		{"previous", []string{"<context file=\"main.go\" revision=\"HEAD\">\n", "println(\"old\")"}},
+		{"blame", []string{"<blame file=\"main.go\" revision=\"HEAD\" lines=\"4-4\">\n", "(Ada ", "println(\"old\")"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := contextBlock(repo, tt.spec, diff.Source{})
+			if err != nil {
+				t.Fatalf("contextBlock(%q) error = %v", tt.spec, err)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(got, want) {
+					t.Errorf("contextBlock(%q) = %q; want it to contain %q", tt.spec, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestGitContextBlockErrors(t *testing.T) {
+	repo := setupGitRepo(t)
+
+	for _, spec := range []string{"log(0)", "HEAD~5:docs/api.md", "src/**/*.go", "missing.md"} {
+		if _, err := contextBlock(repo, spec, diff.Source{}); err == nil {
+			t.Errorf("contextBlock(%q) should fail", spec)
+		}
+	}
+	if _, err := contextBlock(repo, "previous", diff.Source{Kind: diff.SourcePatch}); err == nil {
+		t.Error("contextBlock(previous) of a patch should fail")
+	}
+}
+
+func TestGlobBlockSkipsDeletedFiles(t *testing.T) {
+	repo := setupGitRepo(t)
+	if err := os.WriteFile(filepath.Join(repo.Root, "docs/guide.md"), []byte("# Guide\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(repo.Root, "docs/api.md")); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := contextBlock(repo, "docs/*.md", diff.Source{})
+	if err != nil {
+		t.Fatalf("contextBlock() error = %v", err)
+	}
+	if strings.Contains(got, "docs/api.md") || !strings.Contains(got, "<context file=\"docs/guide.md\">") {
+		t.Errorf("contextBlock() = %q; want docs/guide.md only", got)
+	}
+	if _, err := contextBlock(repo, "docs/api.*", diff.Source{}); err == nil {
+		t.Error("contextBlock() matching only deleted files should fail")
+	}
+}
+
+func TestGitContextBlockEscapesPaths(t *testing.T) {
+	repo := setupGitRepo(t)
+	if err := os.WriteFile(filepath.Join(repo.Root, "docs/a&b.md"), []byte("old\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, repo.Root, "add", "docs/a&b.md")
+	runGit(t, repo.Root, "commit", "-q", "-m", "Add notes")
+	if err := os.WriteFile(filepath.Join(repo.Root, "docs/a&b.md"), []byte("new\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, repo.Root, "add", "docs/a&b.md")
+
+	for spec, want := range map[string]string{
+		"HEAD:docs/a&b.md": "<context file=\"docs/a&amp;b.md\" revision=\"HEAD\">\n",
+		"previous":         "<context file=\"docs/a&amp;b.md\" revision=\"HEAD\">\n",
+		"blame":            "<blame file=\"docs/a&amp;b.md\" revision=\"HEAD\" lines=\"1-1\">\n",
+	} {
+		got, err := contextBlock(repo, spec, diff.Source{})
+		if err != nil {
+			t.Fatalf("contextBlock(%q) error = %v", spec, err)
+		}
+		checkWellFormed(t, got)
+		if !strings.Contains(got, want) {
+			t.Errorf("contextBlock(%q) = %q; want it to contain %q", spec, got, want)
+		}
+	}
+}
+
+func TestBlameBlockUnstaged(t *testing.T) {
+	repo := setupGitRepo(t)
+	notes := filepath.Join(repo.Root, "notes.txt")
+	if err := os.WriteFile(notes, []byte("one\ntwo\nthree\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, repo.Root, "add", "notes.txt")
+	runGit(t, repo.Root, "commit", "-q", "-m", "Add notes")
+	// Two staged lines at the top move "three" to line 5 of the index, which has 3 lines in HEAD
+	if err := os.WriteFile(notes, []byte("zero\nhalf\none\ntwo\nthree\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, repo.Root, "add", "notes.txt")
+	// Unstaged edits of a staged line and of a committed one
+	if err := os.WriteFile(notes, []byte("ZERO\nhalf\none\ntwo\nTHREE\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := contextBlock(repo, "blame", diff.Source{Kind: diff.SourceUnstaged})
+	if err != nil {
+		t.Fatalf("contextBlock(blame) error = %v", err)
+	}
+	for _, want := range []string{
+		"<blame file=\"notes.txt\" revision=\"index\" lines=\"1-1\">\n",
+		"(Not Committed Yet ", ") zero\n",
+		"<blame file=\"notes.txt\" revision=\"index\" lines=\"5-5\">\n",
+		"(Ada ", ") three\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("contextBlock(blame) = %q; want it to contain %q", got, want)
+		}
+	}
+}
@@ -2,8 +2,8 @@ package prompt
 
 import (
 	"fmt"
+	"git-commit/internal/git"
 	"os"
-	"strings"
 )
 
 const defaultAIPrompt = "Generate a branch name and git commit message based on the provided git diff. Strictly follow these rules:\n" +
@@ -26,8 +26,8 @@ const defaultAIPrompt = "Generate a branch name and git commit message based on
 	"   - Body (optional): detailed description of changes\n" +
 	"   - Footer (optional): metadata (ticket number, breaking changes)\n" +
 	"7. **Header Rules:**\n" +
-	"   - Use imperative mood (e.g., \"Add\", \"Fix\", \"Update\")\n" +
-	"   - Start with a capital letter\n" +
+	"   - Use imperative mood (e.g., \"add\", \"fix\", \"update\")\n" +
+	"   - Start the description with a lowercase letter\n" +
 	"   - No period at the end\n" +
 	"   - Max length: 50 characters\n" +
 	"   - Format: \"<type>[scope]: <description>\"\n" +
@@ -119,22 +119,24 @@ const defaultAIPrompt = "Generate a branch name and git commit message based on
 	"```"
 
 // ParseGitCustomCommit reads the prompt.md file from .git-commit and returns a custom prompt
-func parseGitCustomCommitMessage() (string, error) {
+func parseGitCustomCommitMessage(repo *git.Repo) (string, error) {
+	promptPath := repo.ConfigPath("prompt.md")
+
 	// Check if the prompt.md file exists in the .git-commit folder
-	if _, err := os.Stat(".git-commit/prompt.md"); os.IsNotExist(err) {
+	if _, err := os.Stat(promptPath); os.IsNotExist(err) {
 		// If the file doesn't exist, return an empty string
 		return "", nil
 	}
 
 	// Open the file for reading
-	file, err := os.Open(".git-commit/prompt.md")
+	file, err := os.Open(promptPath)
 	if err != nil {
 		return "", fmt.Errorf("failed to open file .git-commit/prompt.md: %v", err)
 	}
 	defer file.Close()
 
 	// Read the entire file into a string
-	content, err := os.ReadFile(".git-commit/prompt.md")
+	content, err := os.ReadFile(promptPath)
 	if err != nil {
 		return "", fmt.Errorf("error reading file .git-commit/prompt.md: %v", err)
 	}
@@ -142,17 +144,14 @@ func parseGitCustomCommitMessage() (string, error) {
 	return string(content), nil
 }
 
-func GetChangesAiPrompt() string {
-	rawPrompt := ""
-	customPrompt, err := parseGitCustomCommitMessage()
+// GetChangesAiPrompt returns the custom prompt from .git-commit/prompt.md, or the default prompt if it is missing or empty.
+// A prompt.md that cannot be read is an error rather than a silent fallback to the default.
+func GetChangesAiPrompt(repo *git.Repo) (string, error) {
+	// prompt.md may extend other prompts or include them, an empty or missing file means the default
+	rawPrompt, err := LoadPrompt(repo, RepoPromptName)
 	if err != nil {
-		fmt.Printf("Error reading custom prompt: %v, using standard\n", err)
-		rawPrompt = defaultAIPrompt
-	} else if strings.TrimSpace(customPrompt) == "" {
-		rawPrompt = defaultAIPrompt
-	} else {
-		rawPrompt = customPrompt
+		return "", fmt.Errorf("error reading custom prompt: %w", err)
 	}
-	
-	return rawPrompt
+
+	return rawPrompt, nil
 }
@@ -2,10 +2,15 @@ package prompt
 
 import (
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+
+	"git-commit/internal/diff"
+	"git-commit/internal/git"
 )
 
-func setupTestDir(t *testing.T) string {
+func setupTestDir(t *testing.T) *git.Repo {
 	// Create a temporary directory in /tmp for testing
 	tempDir, err := os.MkdirTemp("/tmp", "prompt-test-*")
 	if err != nil {
@@ -38,11 +43,11 @@ func setupTestDir(t *testing.T) string {
 		os.RemoveAll(tempDir)
 	})
 	
-	return tempDir
+	return &git.Repo{Root: tempDir}
 }
 
 func TestParseGitCustomCommit_FileExists(t *testing.T) {
-	setupTestDir(t)
+	repo := setupTestDir(t)
 	
 	// Create custom prompt file with some content
 	customContent := `# Custom AI Prompt
@@ -61,7 +66,7 @@ Example: feat(auth): add login functionality
 		t.Fatalf("Failed to create custom prompt file: %v", err)
 	}
 	
-	result, err := parseGitCustomCommitMessage()
+	result, err := parseGitCustomCommitMessage(repo)
 	if err != nil {
 		t.Fatalf("ParseGitCustomCommit() error = %v", err)
 	}
@@ -72,12 +77,12 @@ Example: feat(auth): add login functionality
 }
 
 func TestParseGitCustomCommit_FileDoesNotExist(t *testing.T) {
-	setupTestDir(t)
+	repo := setupTestDir(t)
 	
 	// Ensure .git-commit/prompt.md doesn't exist
 	os.Remove(".git-commit/prompt.md")
 	
-	result, err := parseGitCustomCommitMessage()
+	result, err := parseGitCustomCommitMessage(repo)
 	if err != nil {
 		t.Fatalf("ParseGitCustomCommit() error = %v", err)
 	}
@@ -88,7 +93,7 @@ func TestParseGitCustomCommit_FileDoesNotExist(t *testing.T) {
 }
 
 func TestParseGitCustomCommit_EmptyFile(t *testing.T) {
-	setupTestDir(t)
+	repo := setupTestDir(t)
 	
 	// Create empty prompt file
 	err := os.WriteFile(".git-commit/prompt.md", []byte(""), 0644)
@@ -96,7 +101,7 @@ func TestParseGitCustomCommit_EmptyFile(t *testing.T) {
 		t.Fatalf("Failed to create empty prompt file: %v", err)
 	}
 	
-	result, err := parseGitCustomCommitMessage()
+	result, err := parseGitCustomCommitMessage(repo)
 	if err != nil {
 		t.Fatalf("ParseGitCustomCommit() error = %v", err)
 	}
@@ -107,7 +112,7 @@ func TestParseGitCustomCommit_EmptyFile(t *testing.T) {
 }
 
 func TestParseGitCustomCommit_WhitespaceOnly(t *testing.T) {
-	setupTestDir(t)
+	repo := setupTestDir(t)
 	
 	// Create prompt file with only whitespace
 	whitespaceContent := "   \n\t   \n   "
@@ -116,7 +121,7 @@ func TestParseGitCustomCommit_WhitespaceOnly(t *testing.T) {
 		t.Fatalf("Failed to create whitespace-only prompt file: %v", err)
 	}
 	
-	result, err := parseGitCustomCommitMessage()
+	result, err := parseGitCustomCommitMessage(repo)
 	if err != nil {
 		t.Fatalf("ParseGitCustomCommit() error = %v", err)
 	}
@@ -127,7 +132,7 @@ func TestParseGitCustomCommit_WhitespaceOnly(t *testing.T) {
 }
 
 func TestGetAIPrompt_CustomPromptExists(t *testing.T) {
-	setupTestDir(t)
+	repo := setupTestDir(t)
 	
 	// Create custom prompt file
 	customContent := `# Custom AI Prompt
@@ -137,14 +142,17 @@ This is a custom prompt for testing.`
 		t.Fatalf("Failed to create custom prompt file: %v", err)
 	}
 	
-	result := GetAIPrompt("")
+	result, err := GetAIPrompt(repo, "", diff.Source{})
+	if err != nil {
+		t.Fatalf("GetAIPrompt() error = %v", err)
+	}
 	if result != customContent {
 		t.Errorf("Expected custom prompt, got default prompt")
 	}
 }
 
 func TestGetAIPrompt_CustomPromptEmpty(t *testing.T) {
-	setupTestDir(t)
+	repo := setupTestDir(t)
 	
 	// Create empty custom prompt file
 	err := os.WriteFile(".git-commit/prompt.md", []byte(""), 0644)
@@ -152,14 +160,17 @@ func TestGetAIPrompt_CustomPromptEmpty(t *testing.T) {
 		t.Fatalf("Failed to create empty custom prompt file: %v", err)
 	}
 	
-	result := GetAIPrompt("")
+	result, err := GetAIPrompt(repo, "", diff.Source{})
+	if err != nil {
+		t.Fatalf("GetAIPrompt() error = %v", err)
+	}
 	if result != defaultAIPrompt {
 		t.Errorf("Expected default prompt, got custom prompt")
 	}
 }
 
 func TestGetAIPrompt_CustomPromptWhitespaceOnly(t *testing.T) {
-	setupTestDir(t)
+	repo := setupTestDir(t)
 	
 	// Create custom prompt file with only whitespace
 	whitespaceContent := "   \n\t   \n   "
@@ -168,26 +179,32 @@ func TestGetAIPrompt_CustomPromptWhitespaceOnly(t *testing.T) {
 		t.Fatalf("Failed to create whitespace-only custom prompt file: %v", err)
 	}
 	
-	result := GetAIPrompt("")
+	result, err := GetAIPrompt(repo, "", diff.Source{})
+	if err != nil {
+		t.Fatalf("GetAIPrompt() error = %v", err)
+	}
 	if result != defaultAIPrompt {
 		t.Errorf("Expected default prompt, got custom prompt")
 	}
 }
 
 func TestGetAIPrompt_NoCustomPromptFile(t *testing.T) {
-	setupTestDir(t)
+	repo := setupTestDir(t)
 	
 	// Ensure .git-commit/prompt.md doesn't exist
 	os.Remove(".git-commit/prompt.md")
 	
-	result := GetAIPrompt("")
+	result, err := GetAIPrompt(repo, "", diff.Source{})
+	if err != nil {
+		t.Fatalf("GetAIPrompt() error = %v", err)
+	}
 	if result != defaultAIPrompt {
 		t.Errorf("Expected default prompt, got something else")
 	}
 }
 
 func TestGetAIPrompt_CustomPromptReadError(t *testing.T) {
-	setupTestDir(t)
+	repo := setupTestDir(t)
 	
 	// Create a directory instead of a file to cause read error
 	err := os.Mkdir(".git-commit/prompt.md", 0755)
@@ -195,9 +212,11 @@ func TestGetAIPrompt_CustomPromptReadError(t *testing.T) {
 		t.Fatalf("Failed to create directory instead of file: %v", err)
 	}
 	
-	result := GetAIPrompt("")
-	if result != defaultAIPrompt {
-		t.Errorf("Expected default prompt on read error, got something else")
+	if _, err := GetAIPrompt(repo, "", diff.Source{}); err == nil || !strings.Contains(err.Error(), "prompt.md") {
+		t.Errorf("GetAIPrompt() error = %v; want the prompt.md read error", err)
+	}
+	if _, err := GetChangesAiPrompt(repo); err == nil {
+		t.Error("GetChangesAiPrompt() error = nil; want the read error")
 	}
 }
 
@@ -209,12 +228,11 @@ func TestDefaultAIPromptFormat(t *testing.T) {
 	
 	// Check for key sections
 	expectedSections := []string{
-		"You are a senior software engineer",
-		"generate a branch name",
-		"Branch Name:",
-		"Commit Message:",
-		"<generated_branch_name>",
-		"<generated_commit_message>",
+		"Generate a branch name and git commit message",
+		"**Branch Name Rules:**",
+		"**Output Format:**",
+		"1. Branch name (one line)",
+		"<commit header>",
 	}
 	
 	for _, section := range expectedSections {
@@ -242,7 +260,7 @@ func TestDefaultAIPromptBranchRules(t *testing.T) {
 	}
 	
 	// Check for branch format description
-	if !contains(defaultAIPrompt, "lowercase, hyphen-separated") {
+	if !contains(defaultAIPrompt, "Use lowercase") || !contains(defaultAIPrompt, "Separate words with hyphens") {
 		t.Error("Default prompt missing branch format description")
 	}
 }
@@ -251,8 +269,8 @@ func TestDefaultAIPromptCommitRules(t *testing.T) {
 	// Test that the default prompt includes commit message rules
 	expectedRules := []string{
 		"Conventional Commits",
-		"imperative, present tense",
-		"under 50 characters",
+		"imperative mood",
+		"Max length: 50 characters",
 		"lowercase letter",
 	}
 	
@@ -276,4 +294,24 @@ func indexOf(s, substr string) int {
 		}
 	}
 	return -1
-}
\ No newline at end of file
+}
+
+func TestGetAIPrompt_FromSubdirectory(t *testing.T) {
+	repo := setupTestDir(t)
+
+	customContent := "Custom prompt loaded from the repository root"
+	if err := os.WriteFile(".git-commit/prompt.md", []byte(customContent), 0644); err != nil {
+		t.Fatalf("Failed to create custom prompt file: %v", err)
+	}
+	subDir := filepath.Join("src", "pkg")
+	if err := os.MkdirAll(subDir, 0755); err != nil {
+		t.Fatalf("Failed to create subdirectory: %v", err)
+	}
+	if err := os.Chdir(subDir); err != nil {
+		t.Fatalf("Failed to change to subdirectory: %v", err)
+	}
+
+	if result, err := GetAIPrompt(repo, "", diff.Source{}); err != nil || result != customContent {
+		t.Errorf("Expected prompt from the repository root, got: %s", result)
+	}
+}
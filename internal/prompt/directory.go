@@ -0,0 +1,193 @@
+package prompt
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"git-commit/internal/config"
+	"git-commit/internal/git"
+	"git-commit/internal/ignore"
+)
+
+// binarySniffLen is how much of a file is searched for NUL bytes to detect binaries, as git does
+const binarySniffLen = 8000
+
+// attrEscaper escapes XML attribute values
+var attrEscaper = strings.NewReplacer(`&`, "&amp;", `<`, "&lt;", `>`, "&gt;", `"`, "&quot;")
+
+// directory expands an @context: directory within the context limits of the configuration.
+// Inside the repository it leaves out the files ignored by .gitignore and by the git-commit
+// ignore files; .git is always left out.
+type directory struct {
+	limits   config.Context
+	root     string          // directory symlinks must resolve into: the repository, or the expanded directory outside it
+	rootDesc string          // "repository" or "directory", describing root in skipped symlinks
+	known    map[string]bool // files and directories git does not ignore, nil outside the repository
+	ignored  *ignore.Matcher // git-commit ignore files, nil outside the repository
+	used     int             // bytes of file content included so far
+	omitted  int             // files left out once limits.MaxBytes was reached
+}
+
+// processDirectory returns the nested context of every file under dirPath. Paths in the output
+// are root-relative inside the repository and start with displayPath outside it.
+func processDirectory(repo *git.Repo, dirPath, displayPath string) (string, error) {
+	d := &directory{limits: repo.Settings().Context, root: dirPath, rootDesc: "directory"}
+	base := filepath.ToSlash(displayPath)
+
+	if rel, err := filepath.Rel(repo.Path(), dirPath); repo != nil && err == nil && !strings.HasPrefix(rel, "..") {
+		base = filepath.ToSlash(rel)
+		d.root, d.rootDesc = repo.Path(), "repository"
+		if err := d.loadIgnores(repo, base); err != nil {
+			return "", err
+		}
+	}
+
+	var b strings.Builder
+	if err := d.expand(&b, dirPath, base, 0); err != nil {
+		return "", err
+	}
+	if d.omitted > 0 {
+		fmt.Fprintf(&b, "<omitted files=\"%d\" reason=\"over the context.max_bytes limit of %d bytes\"/>\n", d.omitted, d.limits.MaxBytes)
+	}
+	return b.String(), nil
+}
+
+// loadIgnores reads which files under the root-relative directory base git and the ignore files leave out
+func (d *directory) loadIgnores(repo *git.Repo, base string) error {
+	files, err := git.ListFiles()
+	if err != nil {
+		// Not a git checkout, e.g. an exported tree: only .git is left out
+		return nil
+	}
+	d.known = map[string]bool{}
+	var under []string
+	for _, file := range files {
+		if base != "." && !strings.HasPrefix(file, base+"/") {
+			continue
+		}
+		under = append(under, file)
+		for p := file; p != "." && !d.known[p]; p = path.Dir(p) {
+			d.known[p] = true
+		}
+	}
+	if base != "." && !d.known[base] {
+		// The directory was asked for explicitly, so .gitignore does not hide all of it
+		d.known = nil
+	}
+	d.ignored, err = git.LoadIgnore(repo, under)
+	return err
+}
+
+// excluded reports whether the root-relative path is left out by .gitignore or the ignore files
+func (d *directory) excluded(rel string, isDir bool) bool {
+	if d.known != nil && !d.known[rel] {
+		return true
+	}
+	return d.ignored != nil && d.ignored.Match(rel, isDir)
+}
+
+// expand writes the entries of the directory full, whose path in the output is rel
+func (d *directory) expand(b *strings.Builder, full, rel string, depth int) error {
+	entries, err := os.ReadDir(full)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		childFull := filepath.Join(full, name)
+		childRel := path.Join(rel, name)
+		if name == ".git" || d.excluded(childRel, entry.IsDir()) {
+			continue
+		}
+
+		switch {
+		case entry.Type()&fs.ModeSymlink != 0:
+			if err := d.symlink(b, childFull, childRel); err != nil {
+				return err
+			}
+		case entry.IsDir():
+			if d.limits.MaxDepth > 0 && depth+1 > d.limits.MaxDepth {
+				fmt.Fprintf(b, "<directory name=\"%s\" path=\"%s\" skipped=\"deeper than context.max_depth\"/>\n",
+					attrEscaper.Replace(name), attrEscaper.Replace(childRel))
+				continue
+			}
+			fmt.Fprintf(b, "<directory name=\"%s\" path=\"%s\">\n", attrEscaper.Replace(name), attrEscaper.Replace(childRel))
+			if err := d.expand(b, childFull, childRel, depth+1); err != nil {
+				return err
+			}
+			b.WriteString("</directory>\n")
+		case entry.Type().IsRegular():
+			if err := d.file(b, childFull, childRel); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// symlink includes the file a symlink points to when it stays inside d.root.
+// Directory symlinks are never followed, so links back to a parent cannot loop.
+func (d *directory) symlink(b *strings.Builder, full, rel string) error {
+	target, _ := os.Readlink(full)
+	skip := func(reason string) error {
+		fmt.Fprintf(b, "<symlink path=\"%s\" target=\"%s\" skipped=\"%s\"/>\n",
+			attrEscaper.Replace(rel), attrEscaper.Replace(target), reason)
+		return nil
+	}
+
+	resolved, err := filepath.EvalSymlinks(full)
+	if err != nil {
+		return skip("broken link")
+	}
+	info, err := os.Stat(resolved)
+	if err != nil {
+		return skip("broken link")
+	}
+	if info.IsDir() {
+		return skip("links to a directory")
+	}
+	root, err := filepath.EvalSymlinks(d.root)
+	if err != nil {
+		return err
+	}
+	if inside, err := filepath.Rel(root, resolved); err != nil || strings.HasPrefix(inside, "..") {
+		return skip("links outside the " + d.rootDesc)
+	}
+	return d.file(b, resolved, rel)
+}
+
+// file includes the content of a text file within the size limits
+func (d *directory) file(b *strings.Builder, full, rel string) error {
+	info, err := os.Stat(full)
+	if err != nil {
+		return fmt.Errorf("error reading file %s: %v", rel, err)
+	}
+	size := int(info.Size())
+	if d.limits.MaxFileBytes > 0 && size > d.limits.MaxFileBytes {
+		fmt.Fprintf(b, "<file path=\"%s\" size=\"%d\" skipped=\"larger than context.max_file_bytes\"/>\n", attrEscaper.Replace(rel), size)
+		return nil
+	}
+	if d.omitted > 0 || (d.limits.MaxBytes > 0 && d.used+size > d.limits.MaxBytes) {
+		// Once a file does not fit, later ones are left out too, so the included files stay a prefix
+		d.omitted++
+		return nil
+	}
+
+	content, err := os.ReadFile(full)
+	if err != nil {
+		return fmt.Errorf("error reading file %s: %v", rel, err)
+	}
+	if bytes.IndexByte(content[:min(len(content), binarySniffLen)], 0) >= 0 {
+		fmt.Fprintf(b, "<file path=\"%s\" size=\"%d\" skipped=\"binary\"/>\n", attrEscaper.Replace(rel), size)
+		return nil
+	}
+	d.used += len(content)
+	fmt.Fprintf(b, "<context file=\"%s\">\n%s\n</context>\n", attrEscaper.Replace(rel), content)
+	return nil
+}
@@ -0,0 +1,133 @@
+package prompt
+
+import (
+	"encoding/xml"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"git-commit/internal/config"
+	"git-commit/internal/diff"
+)
+
+// writeTree creates files under root, mapping slash-separated paths to their content
+func writeTree(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		full := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// checkWellFormed fails the test unless output parses as a sequence of XML elements
+func checkWellFormed(t *testing.T, output string) {
+	t.Helper()
+	decoder := xml.NewDecoder(strings.NewReader("<root>" + output + "</root>"))
+	for {
+		if _, err := decoder.Token(); err == io.EOF {
+			return
+		} else if err != nil {
+			t.Fatalf("Output is not well-formed XML: %v\n%s", err, output)
+		}
+	}
+}
+
+func TestDirectoryContext(t *testing.T) {
+	repo := setupTestDir(t)
+	runGit(t, repo.Root, "init", "-q")
+	writeTree(t, repo.Root, map[string]string{
+		".gitignore":          "*.log\n",
+		".git-commit/ignore":  "src/gen/\n",
+		"src/main.go":         "package main",
+		"src/debug.log":       "noise",
+		"src/gen/types.go":    "package gen",
+		"src/api/v1/users.go": "package v1",
+		"src/api/v1/deep/x":   "too deep",
+		"src/logo.png":        "\x89PNG\x00\x00",
+		"src/big.txt":         strings.Repeat("x", 200),
+	})
+	if err := os.Symlink("main.go", filepath.Join(repo.Root, "src", "alias.go")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("..", filepath.Join(repo.Root, "src", "loop")); err != nil {
+		t.Fatal(err)
+	}
+	outside := filepath.Join(t.TempDir(), "secret")
+	writeTree(t, filepath.Dir(outside), map[string]string{"secret": "token"})
+	if err := os.Symlink(outside, filepath.Join(repo.Root, "src", "host")); err != nil {
+		t.Fatal(err)
+	}
+	repo.Config = config.Default()
+	repo.Config.Context = config.Context{MaxFileBytes: 100, MaxDepth: 2}
+
+	got, err := contextBlock(repo, "src", diff.Source{})
+	if err != nil {
+		t.Fatalf("contextBlock() error = %v", err)
+	}
+	checkWellFormed(t, got)
+
+	for _, want := range []string{
+		"<directory name=\"src\" path=\"src\">\n",
+		"<context file=\"src/main.go\">\npackage main\n</context>\n",
+		"<context file=\"src/alias.go\">\npackage main\n</context>\n",
+		"<directory name=\"v1\" path=\"src/api/v1\">\n<directory name=\"deep\" path=\"src/api/v1/deep\" skipped=\"deeper than context.max_depth\"/>\n",
+		"<context file=\"src/api/v1/users.go\">\npackage v1\n</context>\n</directory>\n</directory>\n",
+		"<file path=\"src/logo.png\" size=\"6\" skipped=\"binary\"/>\n",
+		"<file path=\"src/big.txt\" size=\"200\" skipped=\"larger than context.max_file_bytes\"/>\n",
+		"<symlink path=\"src/loop\" target=\"..\" skipped=\"links to a directory\"/>\n",
+		"<symlink path=\"src/host\" target=\"" + outside + "\" skipped=\"links outside the repository\"/>\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Directory context lacks %q:\n%s", want, got)
+		}
+	}
+	for _, unwanted := range []string{"debug.log", "src/gen", ".git/"} {
+		if strings.Contains(got, unwanted) {
+			t.Errorf("Directory context includes %s:\n%s", unwanted, got)
+		}
+	}
+}
+
+func TestFileContextEscapesPath(t *testing.T) {
+	repo := setupTestDir(t)
+	writeTree(t, repo.Root, map[string]string{`notes/"a" & <b>.md`: "notes"})
+
+	got, err := contextBlock(repo, `notes/"a" & <b>.md`, diff.Source{})
+	if err != nil {
+		t.Fatalf("contextBlock() error = %v", err)
+	}
+	checkWellFormed(t, got)
+	if want := "<context file=\"notes/&quot;a&quot; &amp; &lt;b&gt;.md\">\nnotes\n</context>"; got != want {
+		t.Errorf("contextBlock() = %q; want %q", got, want)
+	}
+}
+
+func TestDirectoryContextBudget(t *testing.T) {
+	repo := setupTestDir(t)
+	writeTree(t, repo.Root, map[string]string{
+		"docs/a.md": strings.Repeat("a", 40),
+		"docs/b.md": strings.Repeat("b", 40),
+		"docs/c.md": "c",
+	})
+	repo.Config = config.Default()
+	repo.Config.Context.MaxBytes = 50
+
+	got, err := contextBlock(repo, "docs", diff.Source{})
+	if err != nil {
+		t.Fatalf("contextBlock() error = %v", err)
+	}
+	checkWellFormed(t, got)
+	if !strings.Contains(got, "docs/a.md") || strings.Contains(got, "docs/b.md") || strings.Contains(got, "docs/c.md") {
+		t.Errorf("Expected only docs/a.md within the budget:\n%s", got)
+	}
+	if !strings.Contains(got, "<omitted files=\"2\" reason=\"over the context.max_bytes limit of 50 bytes\"/>\n</directory>") {
+		t.Errorf("Directory context lacks the omitted files note:\n%s", got)
+	}
+}
@@ -0,0 +1,78 @@
+package prompt
+
+import (
+	"fmt"
+	"strings"
+
+	"git-commit/internal/git"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Answer formats a prompt can ask for, see FrontMatter.Output
+const (
+	OutputBranchCommit = "branch-commit" // branch name and commit message, as the default prompt asks for
+	OutputCommit       = "commit"        // commit message only
+	OutputText         = "text"          // free-form text such as documentation
+)
+
+// FrontMatter holds the YAML header of a custom instruction file, enclosed in "---" lines
+type FrontMatter struct {
+	Extends       string   `yaml:"extends"`         // prompt this one builds on: a custom prompt name, "prompt.md" or "default"
+	Description   string   `yaml:"description"`     // one line shown by list and -h
+	Tags          []string `yaml:"tags"`            // keywords shown by list
+	Context       []string `yaml:"context"`         // files or directories the prompt needs, relative to the repository root
+	Model         string   `yaml:"model"`           // preferred model of generate when -model is not given
+	MaxDiffTokens int      `yaml:"max_diff_tokens"` // token budget of the diff in this prompt, overrides diff.max_tokens
+	Output        string   `yaml:"output"`          // format of the expected answer, one of the Output constants
+	Template      bool     `yaml:"template"`        // run the prompt through text/template, see RenderTemplate
+}
+
+// ParseFrontMatter splits the YAML front matter from the body of a prompt file.
+// Content without front matter is returned unchanged with an empty FrontMatter; so is content
+// that merely starts with a "---" rule, unless the text up to the next rule is a YAML mapping.
+func ParseFrontMatter(content string) (FrontMatter, string, error) {
+	var fm FrontMatter
+	rest, ok := strings.CutPrefix(strings.ReplaceAll(content, "\r\n", "\n"), "---\n")
+	if !ok {
+		return fm, content, nil
+	}
+
+	header, body, found := strings.Cut("\n"+rest, "\n---\n")
+	header = strings.TrimPrefix(header, "\n")
+	if !found {
+		// The closing line may end the file
+		if header, found = strings.CutSuffix("\n"+rest, "\n---"); !found {
+			return fm, content, nil
+		}
+		header = strings.TrimPrefix(header, "\n")
+	}
+
+	var node yaml.Node
+	if yaml.Unmarshal([]byte(header), &node) == nil && len(node.Content) > 0 && node.Content[0].Kind != yaml.MappingNode {
+		return fm, content, nil
+	}
+	if err := yaml.Unmarshal([]byte(header), &fm); err != nil {
+		return fm, content, fmt.Errorf("invalid front matter: %v", strings.TrimPrefix(err.Error(), "yaml: "))
+	}
+	switch fm.Output {
+	case "", OutputBranchCommit, OutputCommit, OutputText:
+	default:
+		return fm, content, fmt.Errorf("invalid front matter: output '%s', expected one of %s, %s, %s",
+			fm.Output, OutputBranchCommit, OutputCommit, OutputText)
+	}
+	if fm.MaxDiffTokens < 0 {
+		return fm, content, fmt.Errorf("invalid front matter: max_diff_tokens %d must be at least 0", fm.MaxDiffTokens)
+	}
+	return fm, strings.TrimLeft(body, "\n"), nil
+}
+
+// Metadata returns the front matter of a custom prompt. Values are not inherited through extends:.
+func Metadata(repo *git.Repo, promptName string) (FrontMatter, error) {
+	content, err := LoadCustomPrompt(repo, promptName)
+	if err != nil {
+		return FrontMatter{}, err
+	}
+	fm, _, err := ParseFrontMatter(content)
+	return fm, err
+}
@@ -0,0 +1,87 @@
+package prompt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseFrontMatter(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		extends string
+		body    string
+		valid   bool
+	}{
+		{"No front matter", "Describe the change", "", "Describe the change", true},
+		{"Extends", "---\nextends: prompt.md\n---\n\nBody", "prompt.md", "Body", true},
+		{"Windows line endings", "---\r\nextends: base\r\n---\r\nBody", "base", "Body", true},
+		{"Empty front matter", "---\n---\nBody", "", "Body", true},
+		{"Closed at end of file", "---\nextends: base\n---", "base", "", true},
+		{"Leading rule is not front matter", "---\nSome text\n---\nBody", "", "---\nSome text\n---\nBody", true},
+		{"Unclosed rule is not front matter", "---\nBody", "", "---\nBody", true},
+		{"Invalid YAML", "---\nextends: [base\n---\nBody", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fm, body, err := ParseFrontMatter(tt.content)
+			if (err == nil) != tt.valid {
+				t.Fatalf("ParseFrontMatter() error = %v; want valid = %v", err, tt.valid)
+			}
+			if tt.valid && (fm.Extends != tt.extends || body != tt.body) {
+				t.Errorf("ParseFrontMatter() = %q, %q; want %q, %q", fm.Extends, body, tt.extends, tt.body)
+			}
+		})
+	}
+}
+
+func TestParseFrontMatterMetadata(t *testing.T) {
+	content := `---
+description: Update README.md from the staged changes
+tags: [docs, readme]
+context:
+  - README.md
+model: gpt-4o
+max_diff_tokens: 8000
+output: text
+---
+Body`
+	fm, body, err := ParseFrontMatter(content)
+	if err != nil {
+		t.Fatalf("ParseFrontMatter() error = %v", err)
+	}
+	expected := FrontMatter{
+		Description:   "Update README.md from the staged changes",
+		Tags:          []string{"docs", "readme"},
+		Context:       []string{"README.md"},
+		Model:         "gpt-4o",
+		MaxDiffTokens: 8000,
+		Output:        OutputText,
+	}
+	if !reflect.DeepEqual(fm, expected) || body != "Body" {
+		t.Errorf("ParseFrontMatter() = %+v, %q; want %+v", fm, body, expected)
+	}
+
+	for _, invalid := range []string{"---\noutput: json\n---\n", "---\nmax_diff_tokens: -1\n---\n", "---\ntags: 3\n---\n"} {
+		if _, _, err := ParseFrontMatter(invalid); err == nil {
+			t.Errorf("ParseFrontMatter(%q) should fail", invalid)
+		}
+	}
+}
+
+func TestMetadata(t *testing.T) {
+	repo := setupTestDir(t)
+	writeCustomPrompt(t, repo.Root, "described", "---\ndescription: Explain the change\n---\nBody")
+	writeCustomPrompt(t, repo.Root, "plain", "Body")
+
+	if meta, err := Metadata(repo, "described"); err != nil || meta.Description != "Explain the change" {
+		t.Errorf("Metadata(described) = %+v, %v", meta, err)
+	}
+	if meta, err := Metadata(repo, "plain"); err != nil || !reflect.DeepEqual(meta, FrontMatter{}) {
+		t.Errorf("Metadata(plain) = %+v, %v; want empty front matter", meta, err)
+	}
+	if _, err := Metadata(repo, "missing"); err == nil {
+		t.Error("Metadata() of a missing prompt should fail")
+	}
+}
@@ -0,0 +1,165 @@
+package prompt
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"git-commit/internal/git"
+)
+
+// Names of the prompts a custom instruction can extend besides other custom prompts
+const (
+	RepoPromptName    = "prompt.md" // .git-commit/prompt.md, or the built-in prompt when it is missing or empty
+	DefaultPromptName = "default"   // the built-in prompt
+)
+
+// loader reads prompts, resolving "extends:" front matter and @include: lines.
+// It keeps the chain of prompts being loaded to report cycles.
+type loader struct {
+	repo  *git.Repo
+	stack []string
+}
+
+// enter pushes name on the chain of prompts being loaded, failing if it is already on it
+func (l *loader) enter(name string) error {
+	for _, active := range l.stack {
+		if active == name {
+			return fmt.Errorf("include cycle: %s -> %s", strings.Join(l.stack, " -> "), name)
+		}
+	}
+	l.stack = append(l.stack, name)
+	return nil
+}
+
+// leave pops the prompt pushed by the last enter
+func (l *loader) leave() {
+	l.stack = l.stack[:len(l.stack)-1]
+}
+
+// read returns the raw content of a prompt
+func (l *loader) read(name string) (string, error) {
+	switch name {
+	case DefaultPromptName:
+		return defaultAIPrompt, nil
+	case RepoPromptName:
+		content, err := parseGitCustomCommitMessage(l.repo)
+		if err != nil {
+			return "", err
+		}
+		if strings.TrimSpace(content) == "" {
+			return defaultAIPrompt, nil
+		}
+		return content, nil
+	}
+	return LoadCustomPrompt(l.repo, name)
+}
+
+// load returns the prompt name with its front matter removed, its @include: lines replaced by
+// the included prompts and, when it extends another prompt, merged into that prompt
+func (l *loader) load(name string) (string, error) {
+	if err := l.enter(name); err != nil {
+		return "", err
+	}
+	defer l.leave()
+
+	content, err := l.read(name)
+	if err != nil {
+		return "", err
+	}
+	fm, body, err := ParseFrontMatter(content)
+	if err != nil {
+		return "", fmt.Errorf("%s: %v", name, err)
+	}
+
+	var lines []string
+	for _, line := range strings.Split(body, "\n") {
+		if !strings.Contains(line, "@include:") {
+			lines = append(lines, line)
+			continue
+		}
+		included, err := l.load(strings.TrimSpace(strings.SplitN(line, "@include:", 2)[1]))
+		if err != nil {
+			return "", err
+		}
+		lines = append(lines, strings.TrimRight(included, "\n"))
+	}
+	body = strings.Join(lines, "\n")
+
+	if fm.Extends == "" {
+		return body, nil
+	}
+	parent, err := l.load(fm.Extends)
+	if err != nil {
+		return "", err
+	}
+	return mergeSections(parent, body), nil
+}
+
+// LoadPrompt loads a custom prompt, RepoPromptName or DefaultPromptName, resolving
+// "extends:" front matter and @include: directives. Cycles are reported as errors.
+func LoadPrompt(repo *git.Repo, name string) (string, error) {
+	return (&loader{repo: repo}).load(name)
+}
+
+// headingLine matches a markdown heading
+var headingLine = regexp.MustCompile(`^#{1,6}\s`)
+
+// section is a markdown heading with the lines up to the next heading
+type section struct {
+	heading string // "" for the text before the first heading
+	lines   []string
+}
+
+// splitSections splits markdown at its headings, ignoring "#" lines inside code fences
+func splitSections(text string) []*section {
+	sections := []*section{{}}
+	fenced := false
+	for _, line := range strings.Split(text, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			fenced = !fenced
+		}
+		if !fenced && headingLine.MatchString(line) {
+			sections = append(sections, &section{heading: strings.TrimSpace(line)})
+		}
+		current := sections[len(sections)-1]
+		current.lines = append(current.lines, line)
+	}
+	return sections
+}
+
+// text returns the section as markdown without surrounding blank lines
+func (s *section) text() string {
+	return strings.Trim(strings.Join(s.lines, "\n"), "\n")
+}
+
+// mergeSections applies child on top of parent. A child section whose heading matches a parent
+// heading replaces that section in place, or removes it when the child section has no body.
+// The rest of the child, its text before the first heading and new sections, is appended.
+func mergeSections(parent, child string) string {
+	parentSections := splitSections(parent)
+	byHeading := map[string]*section{}
+	for _, s := range parentSections[1:] {
+		byHeading[s.heading] = s
+	}
+
+	var appended []string
+	for _, s := range splitSections(child) {
+		if target, ok := byHeading[s.heading]; ok && s.heading != "" {
+			target.lines = s.lines
+			if strings.TrimSpace(strings.Join(s.lines[1:], "\n")) == "" {
+				target.lines = nil
+			}
+		} else if text := s.text(); text != "" {
+			appended = append(appended, text)
+		}
+	}
+
+	var parts []string
+	for _, s := range parentSections {
+		if text := s.text(); text != "" {
+			parts = append(parts, text)
+		}
+	}
+	return strings.Join(append(parts, appended...), "\n\n")
+}
@@ -0,0 +1,72 @@
+package prompt
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"git-commit/internal/diff"
+)
+
+func TestMergeSections(t *testing.T) {
+	parent := "You write commit messages.\n\n## Header\nUse 50 characters.\n\n## Body\nExplain why.\n\n## Footer\nAdd trailers."
+	child := "Mention the ticket.\n\n## Header\nUse 72 characters.\n\n## Footer\n\n## Examples\n```\n# not a heading\n```"
+
+	expected := "You write commit messages.\n\n## Header\nUse 72 characters.\n\n## Body\nExplain why.\n\nMention the ticket.\n\n## Examples\n```\n# not a heading\n```"
+	if got := mergeSections(parent, child); got != expected {
+		t.Errorf("mergeSections() = %q; want %q", got, expected)
+	}
+}
+
+func TestLoadPrompt(t *testing.T) {
+	repo := setupTestDir(t)
+	if err := os.WriteFile(filepath.Join(repo.Root, ".git-commit", "prompt.md"), []byte("Base prompt.\n\n## Rules\nBe brief."), 0644); err != nil {
+		t.Fatalf("Failed to write prompt.md: %v", err)
+	}
+	writeCustomPrompt(t, repo.Root, "shared", "Shared guidance.")
+	writeCustomPrompt(t, repo.Root, "docs", "---\nextends: prompt.md\n---\n## Rules\nBe thorough.\n@include: shared\n")
+	writeCustomPrompt(t, repo.Root, "builtin", "---\nextends: default\n---\nExtra rule.")
+	writeCustomPrompt(t, repo.Root, "loop-a", "---\nextends: loop-b\n---\nA")
+	writeCustomPrompt(t, repo.Root, "loop-b", "@include: loop-a")
+	writeCustomPrompt(t, repo.Root, "self", "@include: self")
+
+	got, err := LoadPrompt(repo, "docs")
+	if err != nil {
+		t.Fatalf("LoadPrompt(docs) error = %v", err)
+	}
+	if expected := "Base prompt.\n\n## Rules\nBe thorough.\nShared guidance."; got != expected {
+		t.Errorf("LoadPrompt(docs) = %q; want %q", got, expected)
+	}
+
+	got, err = LoadPrompt(repo, "builtin")
+	if err != nil || !strings.HasPrefix(got, defaultAIPrompt[:40]) || !strings.HasSuffix(got, "\n\nExtra rule.") {
+		t.Errorf("LoadPrompt(builtin) = %q, %v; want the default prompt followed by the extra rule", got, err)
+	}
+
+	for name, cycle := range map[string]string{"loop-a": "loop-a -> loop-b -> loop-a", "self": "self -> self"} {
+		if _, err := LoadPrompt(repo, name); err == nil || !strings.Contains(err.Error(), "include cycle: "+cycle) {
+			t.Errorf("LoadPrompt(%s) error = %v; want include cycle: %s", name, err, cycle)
+		}
+	}
+
+	if _, err := LoadPrompt(repo, "missing"); err == nil {
+		t.Error("LoadPrompt() of a missing prompt should fail")
+	}
+}
+
+func TestGetAIPromptLoadErrors(t *testing.T) {
+	repo := setupTestDir(t)
+	writeCustomPrompt(t, repo.Root, "loop-a", "@include: loop-b\n@diff")
+	writeCustomPrompt(t, repo.Root, "loop-b", "@include: loop-a")
+	writeCustomPrompt(t, repo.Root, "broken", "@include: missing\n@diff")
+
+	for name, want := range map[string]string{
+		"loop-a": "error reading custom prompt 'loop-a': include cycle: loop-a -> loop-b -> loop-a",
+		"broken": "error reading custom prompt 'broken'",
+	} {
+		if got, err := GetAIPrompt(repo, name, diff.Source{}); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("GetAIPrompt(%s) = %q, %v; want an error containing %q", name, got, err, want)
+		}
+	}
+}
@@ -3,76 +3,80 @@ package prompt
 import (
 	"fmt"
 	"git-commit/internal/diff"
+	"git-commit/internal/git"
 	"git-commit/pkg/utils"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 )
 
-// loadCustomPrompt loads a custom prompt from the custom-instructions folder
-// processDirectory recursively processes all files in a directory
-func processDirectory(dirPath string) (string, error) {
-	var result []string
-
-	err := filepath.Walk(dirPath, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-		
-		// Skip the root directory itself
-		if path == dirPath {
-			return nil
-		}
-
-		if info.IsDir() {
-			// For directories, add directory tag
-			relPath, err := filepath.Rel(dirPath, path)
-			if err != nil {
-				return err
-			}
-			result = append(result, fmt.Sprintf("<directory name=\"%s\" path=\"%s\">\n", info.Name(), relPath))
-		} else {
-			// For files, add context tag with content
-			content, err := utils.ReadFileContent(path)
-			if err != nil {
-				return fmt.Errorf("error reading file %s: %v", path, err)
-			}
-			relPath, err := filepath.Rel(dirPath, path)
-			if err != nil {
-				return err
-			}
-			result = append(result, fmt.Sprintf("<context file=\"%s\">\n%s\n</context>\n", relPath, content))
-		}
-		return nil
-	})
-
-	if err != nil {
-		return "", err
-	}
-
-	return strings.Join(result, "\n"), nil
-}
-
-func loadCustomPrompt(promptName string) (string, error) {
+// LoadCustomPrompt loads a custom prompt from the custom-instructions folder of the repository
+func LoadCustomPrompt(repo *git.Repo, promptName string) (string, error) {
 	// Construct the path to the custom prompt file
-	customPromptPath := fmt.Sprintf(".git-commit/custom-instructions/%s.md", promptName)
+	customPromptFile := fmt.Sprintf(".git-commit/custom-instructions/%s.md", promptName)
+	customPromptPath := repo.Path(customPromptFile)
 	
 	// Check if the file exists
 	if _, err := os.Stat(customPromptPath); os.IsNotExist(err) {
-		return "", fmt.Errorf("custom prompt file '%s' not found", customPromptPath)
+		return "", fmt.Errorf("custom prompt file '%s' not found", customPromptFile)
 	}
 
 	// Read the file content
 	content, err := os.ReadFile(customPromptPath)
 	if err != nil {
-		return "", fmt.Errorf("failed to read custom prompt file '%s': %v", customPromptPath, err)
+		return "", fmt.Errorf("failed to read custom prompt file '%s': %v", customPromptFile, err)
 	}
 
 	return string(content), nil
 }
 
-// ProcessMarkdownDirectives processes special directives in markdown content
-func ProcessMarkdownDirectives(content string) (string, error) {
+// contextBlock returns the content of a file, or of every file in a directory, wrapped in context tags,
+// or one of the git sources of gitContextBlock when filePath is not in the working tree.
+// Relative paths are resolved against the repository root.
+func contextBlock(repo *git.Repo, filePath string, src diff.Source) (string, error) {
+	if !isWorkTreePath(repo, filePath) {
+		if block, ok, err := gitContextBlock(repo, filePath, src); ok {
+			return block, err
+		}
+	}
+
+	fullPath := filePath
+	if !filepath.IsAbs(fullPath) {
+		fullPath = repo.Path(filePath)
+	}
+
+	// Get file info to check if it's a directory
+	fileInfo, err := os.Stat(fullPath)
+	if err != nil {
+		return "", fmt.Errorf("error getting file info for %s: %v", filePath, err)
+	}
+
+	if fileInfo.IsDir() {
+		// Handle directory recursively
+		dirContent, err := processDirectory(repo, fullPath, filePath)
+		if err != nil {
+			return "", fmt.Errorf("error processing directory %s: %v", filePath, err)
+		}
+		return fmt.Sprintf("<directory name=\"%s\" path=\"%s\">\n%s</directory>",
+			attrEscaper.Replace(fileInfo.Name()), attrEscaper.Replace(filePath), dirContent), nil
+	}
+
+	// Handle single file
+	fileContent, err := utils.ReadFileContent(fullPath)
+	if err != nil {
+		return "", fmt.Errorf("error reading context file %s: %v", filePath, err)
+	}
+	return fmt.Sprintf("<context file=\"%s\">\n%s\n</context>", attrEscaper.Replace(filePath), fileContent), nil
+}
+
+// diffDirective matches @diff with optional arguments such as @diff(stat, path=src/**)
+var diffDirective = regexp.MustCompile(`@diff(?:\(([^)]*)\))?`)
+
+// ProcessMarkdownDirectives processes special directives in markdown content.
+// Relative @context: paths are resolved against the repository root and @diff inserts the changes of src,
+// narrowed by its arguments, see diff.ParseOptions.
+func ProcessMarkdownDirectives(repo *git.Repo, content string, src diff.Source) (string, error) {
 	lines := strings.Split(content, "\n")
 	var result []string
 
@@ -80,33 +84,20 @@ func ProcessMarkdownDirectives(content string) (string, error) {
 		if strings.Contains(line, "@context:") {
 			// Extract file path after @context:
 			filePath := strings.TrimSpace(strings.Split(line, "@context:")[1])
-			
-			// Get file info to check if it's a directory
-			fileInfo, err := os.Stat(filePath)
+			replacement, err := contextBlock(repo, filePath, src)
 			if err != nil {
-				return "", fmt.Errorf("error getting file info for %s: %v", filePath, err)
+				return "", err
 			}
-
-			if fileInfo.IsDir() {
-				// Handle directory recursively
-				dirContent, err := processDirectory(filePath)
-				if err != nil {
-					return "", fmt.Errorf("error processing directory %s: %v", filePath, err)
-				}
-				replacement := fmt.Sprintf("<directory name=\"%s\" path=\"%s\">\n%s</directory>", 
-					fileInfo.Name(), filePath, dirContent)
-				result = append(result, replacement)
-			} else {
-				// Handle single file
-				fileContent, err := utils.ReadFileContent(filePath)
-				if err != nil {
-					return "", fmt.Errorf("error reading context file %s: %v", filePath, err)
-				}
-				replacement := fmt.Sprintf("<context file=\"%s\">\n%s\n</context>", filePath, fileContent)
-				result = append(result, replacement)
+			result = append(result, replacement)
+		} else if m := diffDirective.FindStringSubmatch(line); m != nil {
+			opts, err := diff.ParseOptions(m[1], src)
+			if err != nil {
+				return "", err
+			}
+			diffOutput, err := diff.GetDiffOutputWith(repo, opts)
+			if err != nil {
+				return "", err
 			}
-		} else if strings.Contains(line, "@diff") {
-			diffOutput := diff.GetDiffOutputWithoutIgnoresFiles()
 			result = append(result, diffOutput)
 		} else {
 			result = append(result, line)
@@ -116,28 +107,79 @@ func ProcessMarkdownDirectives(content string) (string, error) {
 	return strings.Join(result, "\n"), nil
 }
 
-// GetAIPrompt returns the AI prompt (standard or custom) with context files processed
-func GetAIPrompt(promptName string) string {
+// PromptName returns promptName, or the default custom prompt from the repository configuration when it is empty
+func PromptName(repo *git.Repo, promptName string) string {
+	if promptName == "" {
+		return repo.Settings().Prompt
+	}
+	return promptName
+}
+
+// applyMetadata warns about missing context the prompt requires and returns repo with
+// the diff token budget of the prompt's front matter, if it sets one
+func applyMetadata(repo *git.Repo, promptName string) *git.Repo {
+	meta, err := Metadata(repo, promptName)
+	if err != nil {
+		return repo
+	}
+	for _, required := range meta.Context {
+		if !isWorkTreePath(repo, required) && !isGitContext(required) {
+			fmt.Fprintf(os.Stderr, "Warning: prompt '%s' needs %s, which does not exist\n", promptName, required)
+		}
+	}
+	if meta.MaxDiffTokens == 0 || repo == nil {
+		return repo
+	}
+
+	cfg := *repo.Settings()
+	cfg.Diff.MaxTokens = meta.MaxDiffTokens
+	withBudget := *repo
+	withBudget.Config = &cfg
+	return &withBudget
+}
+
+// GetAIPrompt returns the AI prompt (standard or custom) with context files processed,
+// describing the changes of src. Errors of templates and directives are returned, such as
+// diff.ErrNoStagedChanges from @diff, and so are those of a named custom prompt or .git-commit/prompt.md
+// that cannot be loaded, e.g. an include cycle; only an empty custom prompt falls back to the standard one.
+func GetAIPrompt(repo *git.Repo, promptName string, src diff.Source) (string, error) {
 	var rawPrompt string
+	promptName = PromptName(repo, promptName)
 	
-	// If a specific prompt name is provided, try to load it from custom-instructions
+	// If a specific prompt name is provided, try to load it from custom-instructions,
+	// resolving extends: and @include:
 	if promptName != "" {
-		customPrompt, err := loadCustomPrompt(promptName)
+		customPrompt, err := LoadPrompt(repo, promptName)
 		if err != nil {
-			fmt.Printf("Error reading custom prompt '%s': %v, using standard\n", promptName, err)
-			rawPrompt = ""
-		} else if strings.TrimSpace(customPrompt) != "" {
+			return "", fmt.Errorf("error reading custom prompt '%s': %w", promptName, err)
+		}
+		if strings.TrimSpace(customPrompt) != "" {
 			rawPrompt = customPrompt
-		} else {
-			rawPrompt = ""
+			repo = applyMetadata(repo, promptName)
 		}
 	}
-	
-	processedPrompt, err := ProcessMarkdownDirectives(rawPrompt)
+
+	// Fall back to .git-commit/prompt.md or the built-in default prompt
+	templateName := promptName
+	if rawPrompt == "" {
+		repoPrompt, err := GetChangesAiPrompt(repo)
+		if err != nil {
+			return "", err
+		}
+		rawPrompt = repoPrompt
+		templateName = RepoPromptName
+	}
+
+	// Templates run first, so they can emit @context: and @diff lines
+	renderedPrompt, err := RenderTemplate(repo, templateName, rawPrompt, src)
+	if err != nil {
+		return "", fmt.Errorf("error rendering prompt template: %w", err)
+	}
+
+	processedPrompt, err := ProcessMarkdownDirectives(repo, renderedPrompt, src)
 	if err != nil {
-		fmt.Printf("Error processing prompt directives: %v\n", err)
-		return rawPrompt
+		return "", fmt.Errorf("error processing prompt directives: %w", err)
 	}
 	
-	return processedPrompt
+	return processedPrompt, nil
 }
@@ -0,0 +1,189 @@
+package prompt
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"git-commit/internal/branch"
+	"git-commit/internal/diff"
+	"git-commit/internal/git"
+)
+
+// Author is the git identity committing the changes
+type Author struct {
+	Name  string
+	Email string
+}
+
+func (a Author) String() string {
+	if a.Email == "" {
+		return a.Name
+	}
+	return fmt.Sprintf("%s <%s>", a.Name, a.Email)
+}
+
+// TemplateData is the data custom instructions can use as {{.Branch}}, {{.Files}} and so on.
+// Values that need git are computed on first use, so prompts only pay for what they reference.
+type TemplateData struct {
+	repo   *git.Repo
+	src    diff.Source
+	branch *string
+	files  []string
+}
+
+// NewTemplateData returns the template data describing the changes of src in repo
+func NewTemplateData(repo *git.Repo, src diff.Source) *TemplateData {
+	return &TemplateData{repo: repo, src: src}
+}
+
+// Branch returns the current branch, "" on a detached HEAD
+func (d *TemplateData) Branch() string {
+	if d.branch == nil {
+		name, _ := git.CurrentBranch()
+		d.branch = &name
+	}
+	return *d.branch
+}
+
+// Ticket returns the ticket ID of the current branch, e.g. "T-123" for "feature/T-123-add-filters"
+func (d *TemplateData) Ticket() string {
+	return branch.Ticket(d.Branch())
+}
+
+// Author returns the configured git user
+func (d *TemplateData) Author() Author {
+	name, _ := git.ConfigValue("user.name")
+	email, _ := git.ConfigValue("user.email")
+	return Author{Name: name, Email: email}
+}
+
+// Repo returns the name of the repository, the base name of its root directory
+func (d *TemplateData) Repo() string {
+	return filepath.Base(d.repo.Path())
+}
+
+// Source describes the changes the prompt is about, e.g. "staged changes"
+func (d *TemplateData) Source() string {
+	return d.src.String()
+}
+
+// Files returns the changed files without the ignored ones
+func (d *TemplateData) Files() ([]string, error) {
+	if d.files == nil {
+		files, err := diff.ChangedFiles(d.repo, d.src)
+		if err != nil {
+			return nil, err
+		}
+		d.files = files
+	}
+	return d.files, nil
+}
+
+// Packages returns the sorted directories containing changed files, "." for the root
+func (d *TemplateData) Packages() ([]string, error) {
+	files, err := d.Files()
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{}
+	packages := []string{}
+	for _, file := range files {
+		if dir := path.Dir(file); !seen[dir] {
+			seen[dir] = true
+			packages = append(packages, dir)
+		}
+	}
+	sort.Strings(packages)
+	return packages, nil
+}
+
+// renderer executes custom instruction templates, following includes between prompts
+type renderer struct {
+	repo   *git.Repo
+	src    diff.Source
+	data   *TemplateData
+	loader *loader // loads included prompts and tracks the prompts being rendered to detect cycles
+}
+
+// RenderTemplate executes content, the loaded prompt name, as a text/template with the data of
+// NewTemplateData. Templating is opt-in with "template: true" in the front matter of the prompt,
+// so literal "{{" in other prompts, e.g. Mermaid, Helm or Jinja snippets, is kept as written.
+func RenderTemplate(repo *git.Repo, name, content string, src diff.Source) (string, error) {
+	r := &renderer{repo: repo, src: src, data: NewTemplateData(repo, src), loader: &loader{repo: repo}}
+	templated, err := r.templated(name)
+	if err != nil || !templated {
+		return content, err
+	}
+	return r.render(name, content)
+}
+
+// templated reports whether the front matter of a prompt enables templating
+func (r *renderer) templated(name string) (bool, error) {
+	content, err := r.loader.read(name)
+	if err != nil {
+		return false, err
+	}
+	fm, _, err := ParseFrontMatter(content)
+	return fm.Template, err
+}
+
+// render executes one prompt template
+func (r *renderer) render(name, content string) (string, error) {
+	if !strings.Contains(content, "{{") {
+		return content, nil
+	}
+	if err := r.loader.enter(name); err != nil {
+		return "", err
+	}
+	defer r.loader.leave()
+
+	tmpl, err := template.New(name).Option("missingkey=error").Funcs(r.funcs()).Parse(content)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, r.data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// funcs returns the functions available to templates
+func (r *renderer) funcs() template.FuncMap {
+	return template.FuncMap{
+		// diff inserts the changes like the @diff directive, e.g. {{diff "stat, path=src/**"}}
+		"diff": func(args ...string) (string, error) {
+			opts, err := diff.ParseOptions(strings.Join(args, ","), r.src)
+			if err != nil {
+				return "", err
+			}
+			return diff.GetDiffOutputWith(r.repo, opts)
+		},
+		// context inserts a file or directory like the @context: directive
+		"context": func(filePath string) (string, error) {
+			return contextBlock(r.repo, filePath, r.src)
+		},
+		// include inserts another prompt like @include:, rendered with the same data
+		"include": func(promptName string) (string, error) {
+			content, err := r.loader.load(promptName)
+			if err != nil {
+				return "", err
+			}
+			if templated, err := r.templated(promptName); err != nil || !templated {
+				return content, err
+			}
+			return r.render(promptName, content)
+		},
+		"join":      strings.Join,
+		"contains":  strings.Contains,
+		"hasPrefix": strings.HasPrefix,
+		"hasSuffix": strings.HasSuffix,
+		"lower":     strings.ToLower,
+		"upper":     strings.ToUpper,
+		"trim":      strings.TrimSpace,
+	}
+}
@@ -0,0 +1,112 @@
+package prompt
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"git-commit/internal/diff"
+)
+
+// writeCustomPrompt creates .git-commit/custom-instructions/<name>.md under the test repository
+func writeCustomPrompt(t *testing.T, root, name, content string) {
+	t.Helper()
+	dir := filepath.Join(root, ".git-commit", "custom-instructions")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("Failed to create custom-instructions dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name+".md"), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write custom prompt: %v", err)
+	}
+}
+
+func TestRenderTemplate(t *testing.T) {
+	repo := setupTestDir(t)
+	name := "feature/T-42-add-export"
+	writeCustomPrompt(t, repo.Root, "rules", "Keep headers under 50 characters.")
+
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{"Plain markdown is unchanged", "Describe @diff\n{ not a template }", "Describe @diff\n{ not a template }"},
+		{"Variables", "{{.Repo}} on {{.Branch}}", filepath.Base(repo.Root) + " on " + name},
+		{"Conditional", "{{if .Ticket}}Reference {{.Ticket}} in the footer.{{end}}", "Reference T-42 in the footer."},
+		{"Ranges and functions", "{{range .Packages}}[{{.}}]{{end}} {{join .Files \", \"}}", "[api][docs] api/export.go, api/export_test.go, docs/export.md"},
+		{"Include", "{{include \"rules\"}}", "Keep headers under 50 characters."},
+		{"Source", "About the {{.Source}}", "About the staged changes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &renderer{repo: repo, src: diff.Source{}, data: NewTemplateData(repo, diff.Source{}), loader: &loader{repo: repo}}
+			r.data.branch = &name
+			r.data.files = []string{"api/export.go", "api/export_test.go", "docs/export.md"}
+			got, err := r.render("test", tt.content)
+			if err != nil {
+				t.Fatalf("render() error = %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("render() = %q; want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRenderTemplateIncludeCycle(t *testing.T) {
+	repo := setupTestDir(t)
+	writeCustomPrompt(t, repo.Root, "a", "---\ntemplate: true\n---\nA {{include \"b\"}}")
+	writeCustomPrompt(t, repo.Root, "b", "---\ntemplate: true\n---\nB {{include \"a\"}}")
+
+	_, err := RenderTemplate(repo, "a", "A {{include \"b\"}}", diff.Source{})
+	if err == nil || !strings.Contains(err.Error(), "include cycle: a -> b -> a") {
+		t.Errorf("RenderTemplate() error = %v; want an include cycle", err)
+	}
+}
+
+func TestGetAIPrompt_Template(t *testing.T) {
+	repo := setupTestDir(t)
+	writeCustomPrompt(t, repo.Root, "templated", "---\ntemplate: true\n---\nRepository {{.Repo}}{{if false}} hidden{{end}}")
+
+	if got, err := GetAIPrompt(repo, "templated", diff.Source{}); err != nil || got != "Repository "+filepath.Base(repo.Root) {
+		t.Errorf("GetAIPrompt() = %q, %v", got, err)
+	}
+}
+
+func TestGetAIPrompt_LiteralBraces(t *testing.T) {
+	repo := setupTestDir(t)
+	mermaid := "Draw the flow:\n```mermaid\ngraph TD\n  A{{hexagon}} --> B\n```\nHelm: {{ .Values.image }}"
+	writeCustomPrompt(t, repo.Root, "mermaid", mermaid)
+	writeCustomPrompt(t, repo.Root, "wrapper", "---\ntemplate: true\n---\n{{.Repo}}: {{include \"mermaid\"}}")
+	if err := os.WriteFile(filepath.Join(repo.Root, ".git-commit", "prompt.md"), []byte("Jinja: {% if x %}{{ x }}{% endif %}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"mermaid", mermaid},
+		{"", "Jinja: {% if x %}{{ x }}{% endif %}"},
+		{"wrapper", filepath.Base(repo.Root) + ": " + mermaid},
+	}
+	for _, tt := range tests {
+		if got, err := GetAIPrompt(repo, tt.name, diff.Source{}); err != nil || got != tt.expected {
+			t.Errorf("GetAIPrompt(%q) = %q, %v; want %q", tt.name, got, err, tt.expected)
+		}
+	}
+}
+
+func TestTemplateDataPackages(t *testing.T) {
+	data := &TemplateData{files: []string{"main.go", "internal/a/x.go", "internal/a/y.go", "cmd/z.go"}}
+	packages, err := data.Packages()
+	if err != nil {
+		t.Fatalf("Packages() error = %v", err)
+	}
+	if expected := []string{".", "cmd", "internal/a"}; !reflect.DeepEqual(packages, expected) {
+		t.Errorf("Packages() = %q; want %q", packages, expected)
+	}
+}
@@ -0,0 +1,101 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	anthropicDefaultBaseURL   = "https://api.anthropic.com/v1"
+	anthropicDefaultModel     = "claude-3-5-haiku-latest"
+	anthropicDefaultMaxTokens = 1024
+	anthropicAPIKeyEnv        = "ANTHROPIC_API_KEY"
+	anthropicVersion          = "2023-06-01"
+)
+
+// anthropic talks to the Anthropic Messages API
+type anthropic struct {
+	baseURL   string
+	model     string
+	apiKey    string
+	maxTokens int
+	client    *http.Client
+}
+
+type anthropicMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type anthropicRequest struct {
+	Model     string             `json:"model"`
+	MaxTokens int                `json:"max_tokens"`
+	Messages  []anthropicMessage `json:"messages"`
+}
+
+type anthropicResponse struct {
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+}
+
+func newAnthropic(cfg Config) (*anthropic, error) {
+	p := &anthropic{
+		baseURL:   strings.TrimSuffix(cfg.BaseURL, "/"),
+		model:     cfg.Model,
+		apiKey:    valueOrEnv(cfg.APIKey, anthropicAPIKeyEnv),
+		maxTokens: cfg.MaxTokens,
+		client:    cfg.HTTPClient,
+	}
+	if p.baseURL == "" {
+		p.baseURL = anthropicDefaultBaseURL
+	}
+	if p.model == "" {
+		p.model = anthropicDefaultModel
+	}
+	if p.maxTokens == 0 {
+		p.maxTokens = anthropicDefaultMaxTokens
+	}
+	if p.apiKey == "" {
+		return nil, fmt.Errorf("anthropic API key not set, export %s", anthropicAPIKeyEnv)
+	}
+	return p, nil
+}
+
+func (p *anthropic) Name() string {
+	return "anthropic"
+}
+
+func (p *anthropic) Generate(ctx context.Context, req Request) (string, error) {
+	body := anthropicRequest{
+		Model:     p.model,
+		MaxTokens: p.maxTokens,
+		Messages: []anthropicMessage{
+			{Role: "user", Content: userMessage(req)},
+		},
+	}
+
+	headers := map[string]string{
+		"x-api-key":         p.apiKey,
+		"anthropic-version": anthropicVersion,
+	}
+
+	var resp anthropicResponse
+	if err := postJSON(ctx, p.client, p.Name(), p.baseURL+"/messages", headers, body, &resp); err != nil {
+		return "", err
+	}
+
+	var text strings.Builder
+	for _, block := range resp.Content {
+		if block.Type == "text" {
+			text.WriteString(block.Text)
+		}
+	}
+	if text.Len() == 0 {
+		return "", fmt.Errorf("anthropic response contains no text")
+	}
+	return text.String(), nil
+}
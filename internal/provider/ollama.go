@@ -0,0 +1,87 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"strings"
+)
+
+const (
+	ollamaDefaultBaseURL = "http://localhost:11434"
+	ollamaDefaultModel   = "llama3.1"
+	ollamaHostEnv        = "OLLAMA_HOST"
+)
+
+// ollama talks to a local Ollama server through its chat API
+type ollama struct {
+	baseURL   string
+	model     string
+	maxTokens int
+	client    *http.Client
+}
+
+type ollamaMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type ollamaOptions struct {
+	NumPredict int `json:"num_predict,omitempty"`
+}
+
+type ollamaRequest struct {
+	Model    string          `json:"model"`
+	Messages []ollamaMessage `json:"messages"`
+	Stream   bool            `json:"stream"`
+	Options  *ollamaOptions  `json:"options,omitempty"`
+}
+
+type ollamaResponse struct {
+	Message ollamaMessage `json:"message"`
+}
+
+func newOllama(cfg Config) *ollama {
+	p := &ollama{
+		baseURL:   strings.TrimSuffix(cfg.BaseURL, "/"),
+		model:     cfg.Model,
+		maxTokens: cfg.MaxTokens,
+		client:    cfg.HTTPClient,
+	}
+	if p.baseURL == "" {
+		p.baseURL = strings.TrimSuffix(os.Getenv(ollamaHostEnv), "/")
+	}
+	if p.baseURL == "" {
+		p.baseURL = ollamaDefaultBaseURL
+	}
+	if !strings.Contains(p.baseURL, "://") {
+		p.baseURL = "http://" + p.baseURL
+	}
+	if p.model == "" {
+		p.model = ollamaDefaultModel
+	}
+	return p
+}
+
+func (p *ollama) Name() string {
+	return "ollama"
+}
+
+func (p *ollama) Generate(ctx context.Context, req Request) (string, error) {
+	body := ollamaRequest{
+		Model: p.model,
+		Messages: []ollamaMessage{
+			{Role: "user", Content: userMessage(req)},
+		},
+		Stream: false,
+	}
+	if p.maxTokens > 0 {
+		body.Options = &ollamaOptions{NumPredict: p.maxTokens}
+	}
+
+	var resp ollamaResponse
+	if err := postJSON(ctx, p.client, p.Name(), p.baseURL+"/api/chat", nil, body, &resp); err != nil {
+		return "", err
+	}
+	return resp.Message.Content, nil
+}
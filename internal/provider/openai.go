@@ -0,0 +1,90 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	openAIDefaultBaseURL = "https://api.openai.com/v1"
+	openAIDefaultModel   = "gpt-4o-mini"
+	openAIAPIKeyEnv      = "OPENAI_API_KEY"
+)
+
+// openAI talks to any OpenAI-compatible chat completions endpoint
+type openAI struct {
+	baseURL   string
+	model     string
+	apiKey    string
+	maxTokens int
+	client    *http.Client
+}
+
+type openAIMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type openAIRequest struct {
+	Model     string          `json:"model"`
+	Messages  []openAIMessage `json:"messages"`
+	MaxTokens int             `json:"max_tokens,omitempty"`
+}
+
+type openAIResponse struct {
+	Choices []struct {
+		Message openAIMessage `json:"message"`
+	} `json:"choices"`
+}
+
+func newOpenAI(cfg Config) (*openAI, error) {
+	p := &openAI{
+		baseURL:   strings.TrimSuffix(cfg.BaseURL, "/"),
+		model:     cfg.Model,
+		apiKey:    valueOrEnv(cfg.APIKey, openAIAPIKeyEnv),
+		maxTokens: cfg.MaxTokens,
+		client:    cfg.HTTPClient,
+	}
+	if p.baseURL == "" {
+		p.baseURL = openAIDefaultBaseURL
+	}
+	if p.model == "" {
+		p.model = openAIDefaultModel
+	}
+	// Self-hosted compatible servers often run without authentication
+	if p.apiKey == "" && p.baseURL == openAIDefaultBaseURL {
+		return nil, fmt.Errorf("openai API key not set, export %s", openAIAPIKeyEnv)
+	}
+	return p, nil
+}
+
+func (p *openAI) Name() string {
+	return "openai"
+}
+
+func (p *openAI) Generate(ctx context.Context, req Request) (string, error) {
+	body := openAIRequest{
+		Model: p.model,
+		Messages: []openAIMessage{
+			{Role: "user", Content: userMessage(req)},
+		},
+		MaxTokens: p.maxTokens,
+	}
+
+	headers := map[string]string{}
+	if p.apiKey != "" {
+		headers["Authorization"] = "Bearer " + p.apiKey
+	}
+
+	var resp openAIResponse
+	if err := postJSON(ctx, p.client, p.Name(), p.baseURL+"/chat/completions", headers, body, &resp); err != nil {
+		return "", err
+	}
+
+	if len(resp.Choices) == 0 {
+		return "", fmt.Errorf("openai response contains no choices")
+	}
+	return resp.Choices[0].Message.Content, nil
+}
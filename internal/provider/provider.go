@@ -0,0 +1,157 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// DefaultTimeout is used when Config.Timeout is not set
+const DefaultTimeout = 60 * time.Second
+
+// Provider generates text from a prompt using a language model
+type Provider interface {
+	// Name returns the provider identifier, e.g. "openai"
+	Name() string
+	// Generate sends the request to the model and returns the generated text
+	Generate(ctx context.Context, req Request) (string, error)
+}
+
+// Request is the input sent to a provider
+type Request struct {
+	// Prompt holds the instructions, e.g. the output of prompt.GetChangesAiPrompt
+	Prompt string
+	// Diff holds the changes to analyze; it may be empty when the prompt already embeds them
+	Diff string
+}
+
+// Config selects and configures a provider
+type Config struct {
+	Name       string        // "openai", "anthropic" or "ollama"
+	Model      string        // model identifier, provider default when empty
+	BaseURL    string        // API base URL, provider default when empty
+	APIKey     string        // API key, read from the provider's environment variable when empty
+	MaxTokens  int           // maximum tokens to generate, provider default when zero
+	Timeout    time.Duration // request timeout, DefaultTimeout when zero
+	HTTPClient *http.Client  // HTTP client, built from Timeout when nil
+}
+
+// APIError is returned when a provider responds with a non-success status code
+type APIError struct {
+	Provider   string
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s API error (status %d): %s", e.Provider, e.StatusCode, e.Message)
+}
+
+// Names returns the identifiers of the supported providers
+func Names() []string {
+	return []string{"openai", "anthropic", "ollama"}
+}
+
+// New creates the provider selected by cfg.Name
+func New(cfg Config) (Provider, error) {
+	if cfg.HTTPClient == nil {
+		timeout := cfg.Timeout
+		if timeout == 0 {
+			timeout = DefaultTimeout
+		}
+		cfg.HTTPClient = &http.Client{Timeout: timeout}
+	}
+
+	switch strings.ToLower(cfg.Name) {
+	case "openai":
+		return newOpenAI(cfg)
+	case "anthropic":
+		return newAnthropic(cfg)
+	case "ollama":
+		return newOllama(cfg), nil
+	case "":
+		return nil, fmt.Errorf("no provider specified, expected one of: %s", strings.Join(Names(), ", "))
+	default:
+		return nil, fmt.Errorf("unknown provider '%s', expected one of: %s", cfg.Name, strings.Join(Names(), ", "))
+	}
+}
+
+// userMessage combines the prompt and the diff into a single message for the model
+func userMessage(req Request) string {
+	if strings.TrimSpace(req.Diff) == "" {
+		return req.Prompt
+	}
+	return req.Prompt + "\n\n" + req.Diff
+}
+
+// valueOrEnv returns value, or the environment variable key when value is empty
+func valueOrEnv(value, key string) string {
+	if value != "" {
+		return value
+	}
+	return os.Getenv(key)
+}
+
+// postJSON sends body as JSON to url and decodes a successful JSON response into out
+func postJSON(ctx context.Context, client *http.Client, providerName, url string, headers map[string]string, body, out interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s request: %v", providerName, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create %s request: %v", providerName, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s request failed: %v", providerName, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read %s response: %v", providerName, err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &APIError{Provider: providerName, StatusCode: resp.StatusCode, Message: errorMessage(data)}
+	}
+
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to decode %s response: %v", providerName, err)
+	}
+	return nil
+}
+
+// errorMessage extracts a human readable message from an error response body
+func errorMessage(data []byte) string {
+	var body struct {
+		Error json.RawMessage `json:"error"`
+	}
+	if err := json.Unmarshal(data, &body); err == nil && len(body.Error) > 0 {
+		// OpenAI and Anthropic nest the message in an object, Ollama uses a plain string
+		var nested struct {
+			Message string `json:"message"`
+		}
+		if err := json.Unmarshal(body.Error, &nested); err == nil && nested.Message != "" {
+			return nested.Message
+		}
+		var plain string
+		if err := json.Unmarshal(body.Error, &plain); err == nil && plain != "" {
+			return plain
+		}
+	}
+	return strings.TrimSpace(string(data))
+}
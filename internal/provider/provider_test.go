@@ -0,0 +1,161 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newTestServer starts a stand-in API server that checks the request path and replies with response
+func newTestServer(t *testing.T, path string, status int, response string, check func(r *http.Request, body map[string]interface{})) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != path {
+			t.Errorf("Expected request to %s, got %s", path, r.URL.Path)
+		}
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("Failed to decode request body: %v", err)
+		}
+		if check != nil {
+			check(r, body)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(response))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// firstMessageContent returns the content of the first chat message in a request body
+func firstMessageContent(t *testing.T, body map[string]interface{}) string {
+	messages, ok := body["messages"].([]interface{})
+	if !ok || len(messages) == 0 {
+		t.Fatalf("Expected messages in request body, got %v", body["messages"])
+	}
+	message := messages[0].(map[string]interface{})
+	return message["content"].(string)
+}
+
+var testRequest = Request{Prompt: "Generate a commit message", Diff: "diff --git a/main.go b/main.go"}
+
+func TestOpenAIGenerate(t *testing.T) {
+	server := newTestServer(t, "/v1/chat/completions", http.StatusOK,
+		`{"choices":[{"message":{"role":"assistant","content":"feat: add main"}}]}`,
+		func(r *http.Request, body map[string]interface{}) {
+			if got := r.Header.Get("Authorization"); got != "Bearer test-key" {
+				t.Errorf("Expected bearer token, got %q", got)
+			}
+			if body["model"] != "test-model" {
+				t.Errorf("Expected model test-model, got %v", body["model"])
+			}
+			content := firstMessageContent(t, body)
+			if !strings.Contains(content, testRequest.Prompt) || !strings.Contains(content, testRequest.Diff) {
+				t.Errorf("Expected prompt and diff in message, got %q", content)
+			}
+		})
+
+	p, err := New(Config{Name: "openai", BaseURL: server.URL + "/v1", Model: "test-model", APIKey: "test-key"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	result, err := p.Generate(context.Background(), testRequest)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if result != "feat: add main" {
+		t.Errorf("Expected generated text, got %q", result)
+	}
+}
+
+func TestAnthropicGenerate(t *testing.T) {
+	server := newTestServer(t, "/v1/messages", http.StatusOK,
+		`{"content":[{"type":"text","text":"fix: handle nil"}]}`,
+		func(r *http.Request, body map[string]interface{}) {
+			if got := r.Header.Get("x-api-key"); got != "test-key" {
+				t.Errorf("Expected x-api-key header, got %q", got)
+			}
+			if got := r.Header.Get("anthropic-version"); got == "" {
+				t.Error("Expected anthropic-version header")
+			}
+			if body["max_tokens"] != float64(anthropicDefaultMaxTokens) {
+				t.Errorf("Expected default max_tokens, got %v", body["max_tokens"])
+			}
+		})
+
+	p, err := New(Config{Name: "anthropic", BaseURL: server.URL + "/v1", APIKey: "test-key"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	result, err := p.Generate(context.Background(), testRequest)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if result != "fix: handle nil" {
+		t.Errorf("Expected generated text, got %q", result)
+	}
+}
+
+func TestOllamaGenerate(t *testing.T) {
+	server := newTestServer(t, "/api/chat", http.StatusOK,
+		`{"message":{"role":"assistant","content":"docs: update readme"}}`,
+		func(r *http.Request, body map[string]interface{}) {
+			if body["stream"] != false {
+				t.Errorf("Expected streaming to be disabled, got %v", body["stream"])
+			}
+		})
+
+	p, err := New(Config{Name: "ollama", BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	result, err := p.Generate(context.Background(), Request{Prompt: "only prompt"})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if result != "docs: update readme" {
+		t.Errorf("Expected generated text, got %q", result)
+	}
+}
+
+func TestGenerateAPIError(t *testing.T) {
+	server := newTestServer(t, "/v1/chat/completions", http.StatusUnauthorized,
+		`{"error":{"message":"invalid api key"}}`, nil)
+
+	p, err := New(Config{Name: "openai", BaseURL: server.URL + "/v1", APIKey: "bad-key"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	_, err = p.Generate(context.Background(), testRequest)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected *APIError, got %v", err)
+	}
+	if apiErr.StatusCode != http.StatusUnauthorized || apiErr.Message != "invalid api key" {
+		t.Errorf("Unexpected API error: %+v", apiErr)
+	}
+}
+
+func TestNewUnknownProvider(t *testing.T) {
+	if _, err := New(Config{Name: "unknown"}); err == nil {
+		t.Error("Expected error for unknown provider")
+	}
+	if _, err := New(Config{}); err == nil {
+		t.Error("Expected error when no provider is specified")
+	}
+}
+
+func TestNewMissingAPIKey(t *testing.T) {
+	t.Setenv(anthropicAPIKeyEnv, "")
+	if _, err := New(Config{Name: "anthropic"}); err == nil {
+		t.Error("Expected error when the Anthropic API key is missing")
+	}
+}
@@ -0,0 +1,218 @@
+// Package review runs the interactive loop in which the user reviews a generated branch name and
+// commit message, and accepts, edits or regenerates it, or switches to another prompt.
+package review
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"git-commit/internal/parser"
+	"git-commit/internal/provider"
+)
+
+// ErrAborted is returned by Run when the user aborts the review
+var ErrAborted = errors.New("review aborted")
+
+// Proposal is a generated branch name and commit message
+type Proposal struct {
+	Branch  string // "" when the answer of the model names no branch
+	Message string
+}
+
+// Session holds what a review needs: the staged changes, the model and the prompts to choose from
+type Session struct {
+	In      io.Reader
+	Out     io.Writer
+	Summary string   // staged file summary, see diff.Diff.Summary
+	Prompt  string   // prompt the first message is generated with, "" for the default prompt
+	Prompts []string // custom prompts the user can switch to
+
+	Provider provider.Provider
+	// Request builds the provider request of a prompt, "" for the default prompt
+	Request func(promptName string) (provider.Request, error)
+	// Edit opens a message in the editor and returns the saved text, see output.Edit
+	Edit func(text string) (string, error)
+	// Check, when set, rejects a proposal the user tries to accept, e.g. an invalid branch name
+	Check func(p *Proposal) error
+
+	lines *bufio.Reader
+}
+
+// Run generates a proposal and asks what to do with it until the user accepts or aborts.
+// It returns the accepted proposal, or ErrAborted.
+func (s *Session) Run(ctx context.Context) (*Proposal, error) {
+	s.lines = bufio.NewReader(s.In)
+	fmt.Fprintf(s.Out, "Staged changes:\n%s\n", s.Summary)
+
+	p, err := s.generate(ctx, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		s.show(p)
+		answer, err := s.ask("[a]ccept, [e]dit, [r]egenerate, [p]rompt, [q]uit? ")
+		if err != nil {
+			return nil, ErrAborted
+		}
+
+		switch strings.ToLower(answer) {
+		case "a", "accept", "y", "yes":
+			if s.Check != nil {
+				if err := s.Check(p); err != nil {
+					fmt.Fprintf(s.Out, "Cannot accept: %v\n", err)
+					continue
+				}
+			}
+			return p, nil
+		case "e", "edit":
+			edited, err := s.Edit(p.Message + "\n")
+			if err != nil {
+				fmt.Fprintf(s.Out, "Edit failed: %v\n", err)
+			} else if strings.TrimSpace(edited) == "" {
+				fmt.Fprintln(s.Out, "The edited message is empty, keeping the previous one.")
+			} else {
+				p.Message = strings.TrimSpace(edited)
+			}
+		case "r", "regenerate":
+			feedback, err := s.ask("Feedback for the model (empty to just regenerate): ")
+			if err != nil {
+				return nil, ErrAborted
+			}
+			p = s.regenerate(ctx, feedback, p)
+		case "p", "prompt":
+			if name, ok := s.choosePrompt(); ok {
+				previous := s.Prompt
+				s.Prompt = name
+				if next := s.regenerate(ctx, "", p); next == p {
+					// Keep the prompt the shown message was generated with
+					s.Prompt = previous
+				} else {
+					p = next
+				}
+			}
+		case "q", "quit", "abort", "n", "no":
+			return nil, ErrAborted
+		default:
+			fmt.Fprintf(s.Out, "Unknown choice %q.\n", answer)
+		}
+	}
+}
+
+// regenerate returns a new proposal, or current when generation fails
+func (s *Session) regenerate(ctx context.Context, feedback string, current *Proposal) *Proposal {
+	p, err := s.generate(ctx, feedback, current)
+	if err != nil {
+		fmt.Fprintf(s.Out, "Generation failed: %v\n", err)
+		return current
+	}
+	return p
+}
+
+// generate asks the model for a proposal with the current prompt. With feedback, the previous
+// proposal and the feedback are appended to the prompt so the model revises its answer.
+func (s *Session) generate(ctx context.Context, feedback string, previous *Proposal) (*Proposal, error) {
+	req, err := s.Request(s.Prompt)
+	if err != nil {
+		return nil, err
+	}
+	if feedback != "" {
+		req.Prompt = Revise(req.Prompt, format(previous), feedback)
+	}
+
+	fmt.Fprintf(s.Out, "Generating with %s...\n", s.Provider.Name())
+	answer, err := s.Provider.Generate(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := parser.ParseAnswer(strings.TrimSpace(answer))
+	if err != nil {
+		return nil, fmt.Errorf("model answer is not a commit message: %w", err)
+	}
+	return &Proposal{Branch: parsed.Branch, Message: strings.TrimSpace(parsed.Commit.String())}, nil
+}
+
+// Revise appends a previous answer and the feedback of the user on it to a prompt
+func Revise(prompt, previous, feedback string) string {
+	return fmt.Sprintf("%s\n\nYou previously answered:\n<previous_answer>\n%s\n</previous_answer>\n\n"+
+		"Revise that answer following this feedback, keeping the same output format:\n<feedback>\n%s\n</feedback>",
+		prompt, previous, feedback)
+}
+
+// format renders a proposal in the branch and commit format the default prompt asks for
+func format(p *Proposal) string {
+	if p.Branch == "" {
+		return p.Message
+	}
+	return fmt.Sprintf("Branch: %s\n\n%s", p.Branch, p.Message)
+}
+
+// show prints a proposal
+func (s *Session) show(p *Proposal) {
+	branch := p.Branch
+	if branch == "" {
+		branch = "(none)"
+	}
+	fmt.Fprintf(s.Out, "\nBranch: %s\n\n%s\n\n", branch, indent(p.Message))
+}
+
+// choosePrompt lets the user pick the default or a custom prompt, ok is false when the choice is
+// invalid or the current prompt
+func (s *Session) choosePrompt() (name string, ok bool) {
+	choices := append([]string{""}, s.Prompts...)
+	for i, name := range choices {
+		marker := " "
+		if name == s.Prompt {
+			marker = "*"
+		}
+		if name == "" {
+			name = "(default prompt)"
+		}
+		fmt.Fprintf(s.Out, "%s %d) %s\n", marker, i+1, name)
+	}
+	answer, err := s.ask("Prompt number or name: ")
+	if err != nil || answer == "" {
+		return "", false
+	}
+
+	chosen := -1
+	if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(choices) {
+		chosen = n - 1
+	}
+	for i, name := range choices {
+		if name != "" && name == answer {
+			chosen = i
+		}
+	}
+	if chosen < 0 {
+		fmt.Fprintf(s.Out, "Unknown prompt %q.\n", answer)
+		return "", false
+	}
+	return choices[chosen], choices[chosen] != s.Prompt
+}
+
+// ask prints a question and returns the trimmed answer, or io.EOF once the input ends
+func (s *Session) ask(question string) (string, error) {
+	fmt.Fprint(s.Out, question)
+	answer, err := s.lines.ReadString('\n')
+	if err != nil && answer == "" {
+		fmt.Fprintln(s.Out)
+		return "", err
+	}
+	return strings.TrimSpace(answer), nil
+}
+
+// indent indents every line of a message so it stands out from the menu
+func indent(message string) string {
+	lines := strings.Split(message, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = "    " + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,146 @@
+package review
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"git-commit/internal/parser"
+	"git-commit/internal/provider"
+)
+
+// scripted is a provider that returns its answers in order and records the requests
+type scripted struct {
+	answers  []string
+	requests []provider.Request
+}
+
+func (s *scripted) Name() string { return "scripted" }
+
+func (s *scripted) Generate(ctx context.Context, req provider.Request) (string, error) {
+	s.requests = append(s.requests, req)
+	if len(s.answers) == 0 {
+		return "", errors.New("no more answers")
+	}
+	answer := s.answers[0]
+	s.answers = s.answers[1:]
+	return answer, nil
+}
+
+// newSession returns a session answering with the scripted provider and reading input
+func newSession(p *scripted, input string) (*Session, *bytes.Buffer) {
+	var out bytes.Buffer
+	return &Session{
+		In:       strings.NewReader(input),
+		Out:      &out,
+		Summary:  "M  main.go (+1 -0)\n1 files changed, 1 insertions(+), 0 deletions(-)\n",
+		Prompts:  []string{"api", "docs"},
+		Provider: p,
+		Request: func(promptName string) (provider.Request, error) {
+			return provider.Request{Prompt: "prompt " + promptName, Diff: "diff"}, nil
+		},
+		Edit: func(text string) (string, error) { return text + "\nRefs: #42\n", nil },
+	}, &out
+}
+
+func TestRun(t *testing.T) {
+	first := "Branch: feat/x\n\nfeat: add x"
+	second := "Branch: feat/login\n\nfeat: add login"
+	tests := []struct {
+		name     string
+		answers  []string
+		input    string
+		expected *Proposal
+		prompts  []string // prompts of the provider requests
+	}{
+		{"Accept", []string{first}, "a\n", &Proposal{Branch: "feat/x", Message: "feat: add x"}, []string{"prompt "}},
+		{"Edit then accept", []string{first}, "e\na\n", &Proposal{Branch: "feat/x", Message: "feat: add x\n\nRefs: #42"}, []string{"prompt "}},
+		{"Regenerate", []string{first, second}, "r\n\na\n", &Proposal{Branch: "feat/login", Message: "feat: add login"}, []string{"prompt ", "prompt "}},
+		{"Switch prompt by name", []string{first, second}, "p\napi\ny\n", &Proposal{Branch: "feat/login", Message: "feat: add login"}, []string{"prompt ", "prompt api"}},
+		{"Switch prompt by number", []string{first, second}, "p\n3\na\n", &Proposal{Branch: "feat/login", Message: "feat: add login"}, []string{"prompt ", "prompt docs"}},
+		{"Failed regeneration keeps the message", []string{first}, "r\n\na\n", &Proposal{Branch: "feat/x", Message: "feat: add x"}, []string{"prompt ", "prompt "}},
+		{"Unknown choice", []string{first}, "x\np\nmark\na\n", &Proposal{Branch: "feat/x", Message: "feat: add x"}, []string{"prompt "}},
+		{"Message-only answer", []string{"fix: add x"}, "a\n", &Proposal{Message: "fix: add x"}, []string{"prompt "}},
+		{"Unparsed regeneration keeps the message", []string{first, "add x"}, "r\n\na\n", &Proposal{Branch: "feat/x", Message: "feat: add x"}, []string{"prompt ", "prompt "}},
+		{"Abort", []string{first}, "q\n", nil, []string{"prompt "}},
+		{"End of input", []string{first}, "", nil, []string{"prompt "}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &scripted{answers: tt.answers}
+			s, out := newSession(p, tt.input)
+			got, err := s.Run(context.Background())
+			if tt.expected == nil {
+				if !errors.Is(err, ErrAborted) {
+					t.Errorf("Run() = %v, %v; want ErrAborted", got, err)
+				}
+			} else if err != nil || *got != *tt.expected {
+				t.Errorf("Run() = %+v, %v; want %+v\n%s", got, err, tt.expected, out)
+			}
+
+			var prompts []string
+			for _, req := range p.requests {
+				prompts = append(prompts, req.Prompt)
+			}
+			if strings.Join(prompts, "|") != strings.Join(tt.prompts, "|") {
+				t.Errorf("Requested prompts %q; want %q", prompts, tt.prompts)
+			}
+		})
+	}
+}
+
+func TestRunUnparsedAnswer(t *testing.T) {
+	s, out := newSession(&scripted{answers: []string{"Sure, here is a message: add x"}}, "a\n")
+	if got, err := s.Run(context.Background()); !errors.Is(err, parser.ErrInvalidHeader) {
+		t.Errorf("Run() = %+v, %v; want ErrInvalidHeader\n%s", got, err, out)
+	}
+}
+
+func TestRunFeedback(t *testing.T) {
+	p := &scripted{answers: []string{"Branch: feat/x\n\nfeat: add x", "Branch: fix/x\n\nfix: repair x"}}
+	s, out := newSession(p, "r\nthis is a fix, not a feature\na\n")
+	got, err := s.Run(context.Background())
+	if err != nil || got.Branch != "fix/x" {
+		t.Fatalf("Run() = %+v, %v\n%s", got, err, out)
+	}
+
+	revised := p.requests[1].Prompt
+	for _, want := range []string{"prompt \n", "<previous_answer>\nBranch: feat/x\n\nfeat: add x\n</previous_answer>", "<feedback>\nthis is a fix, not a feature\n</feedback>"} {
+		if !strings.Contains(revised, want) {
+			t.Errorf("Revised prompt lacks %q:\n%s", want, revised)
+		}
+	}
+	for _, want := range []string{"Staged changes:\nM  main.go (+1 -0)\n", "Branch: feat/x\n\n    feat: add x\n", "Branch: fix/x\n\n    fix: repair x\n"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Output lacks %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestRunCheck(t *testing.T) {
+	p := &scripted{answers: []string{"Branch: misc/x\n\nfeat: add x", "Branch: feature/x\n\nfeat: add x"}}
+	s, out := newSession(p, "a\nr\n\na\n")
+	s.Check = func(p *Proposal) error {
+		if !strings.HasPrefix(p.Branch, "feature/") {
+			return errors.New("unknown prefix")
+		}
+		return nil
+	}
+	got, err := s.Run(context.Background())
+	if err != nil || got.Branch != "feature/x" {
+		t.Fatalf("Run() = %+v, %v\n%s", got, err, out)
+	}
+	if !strings.Contains(out.String(), "Cannot accept: unknown prefix\n") {
+		t.Errorf("Output lacks the rejection:\n%s", out)
+	}
+}
+
+func TestRunGenerationError(t *testing.T) {
+	s, _ := newSession(&scripted{}, "a\n")
+	if _, err := s.Run(context.Background()); err == nil || errors.Is(err, ErrAborted) {
+		t.Errorf("Run() error = %v; want the provider error", err)
+	}
+}
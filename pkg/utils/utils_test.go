@@ -1,99 +0,0 @@
-package utils
-
-import (
-	"testing"
-)
-
-func TestGlobMatch(t *testing.T) {
-	tests := []struct {
-		name     string
-		pattern  string
-		path     string
-		expected bool
-	}{
-		{"Exact match", "file.txt", "file.txt", true},
-		{"Exact match no match", "file.txt", "other.txt", false},
-		{"Directory pattern", "src/", "src/main.go", true},
-		{"Directory pattern exact", "src/", "src", true},
-		{"Directory pattern no match", "src/", "test/main.go", false},
-		{"Wildcard prefix", "*.go", "main.go", true},
-		{"Wildcard prefix no match", "*.go", "main.txt", false},
-		{"Wildcard suffix", "test.*", "test.txt", true},
-		{"Wildcard suffix no match", "test.*", "main.txt", false},
-		{"Complex pattern", "src/*.go", "src/main.go", true}, // Should match due to implementation
-		{"Empty pattern", "", "file.txt", false},
-		{"Empty path", "file.txt", "", false},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := GlobMatch(tt.pattern, tt.path)
-			if result != tt.expected {
-				t.Errorf("GlobMatch(%q, %q) = %v; want %v", tt.pattern, tt.path, result, tt.expected)
-			}
-		})
-	}
-}
-
-func TestGlobMatchDirectory(t *testing.T) {
-	tests := []struct {
-		name     string
-		pattern  string
-		path     string
-		expected bool
-	}{
-		{"Directory with slash", "src/", "src/main.go", true},
-		{"Directory without slash", "src", "src/main.go", true},
-		{"Directory exact match", "src", "src", true},
-		{"Different directory", "test/", "src/main.go", false},
-		{"Subdirectory", "src/app", "src/app/main.go", false}, // Should not match due to implementation
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := GlobMatch(tt.pattern, tt.path)
-			if result != tt.expected {
-				t.Errorf("GlobMatch(%q, %q) = %v; want %v", tt.pattern, tt.path, result, tt.expected)
-			}
-		})
-	}
-}
-
-func TestGlobMatchWildcard(t *testing.T) {
-	tests := []struct {
-		name     string
-		pattern  string
-		path     string
-		expected bool
-	}{
-		{"Prefix wildcard", "*.log", "app.log", true},
-		{"Prefix wildcard no match", "*.log", "app.txt", false},
-		{"Suffix wildcard", "test.*", "test.txt", true},
-		{"Suffix wildcard no match", "test.*", "main.txt", false},
-		{"No wildcard", "file.txt", "file.txt", true},
-		{"No wildcard no match", "file.txt", "other.txt", false},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := GlobMatch(tt.pattern, tt.path)
-			if result != tt.expected {
-				t.Errorf("GlobMatch(%q, %q) = %v; want %v", tt.pattern, tt.path, result, tt.expected)
-			}
-		})
-	}
-}
-
-func TestPrintf(t *testing.T) {
-	// Test printf function - this is a simple wrapper around fmt.Printf
-	// We can't easily capture stdout in unit tests without additional setup
-	// So we'll just verify it doesn't panic
-	defer func() {
-		if r := recover(); r != nil {
-			t.Errorf("printf panicked: %v", r)
-		}
-	}()
-
-	printf("Test message")
-	printf("Test with args: %d, %s", 42, "hello")
-}
\ No newline at end of file